@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/brewinski/home-lab-server/src/metro-volleyball-bot/flags"
+	"github.com/brewinski/home-lab-server/src/metro-volleyball-bot/subscription"
 	"github.com/bwmarrin/discordgo"
 )
 
@@ -18,6 +19,17 @@ type Config struct {
 	MonitorUrl     string
 	UpdatesChannel string
 	TickSpeed      time.Duration
+	// Subscriptions are the guilds' choices of what to follow. Optional, every guild gets
+	// the default subscription without it.
+	Subscriptions *subscription.Registry
+	// Default is followed by guilds that haven't subscribed, its updates are sent to the
+	// UpdatesChannel.
+	Default subscription.Subscription
+	// Commands are registered with every guild as it becomes available. Optional.
+	Commands *Registry
+	// GlobalCommands registers the commands once for every guild instead. Discord can
+	// take a while to show changes to global commands.
+	GlobalCommands bool
 }
 
 func New(cfg Config) *Bot {
@@ -27,26 +39,120 @@ func New(cfg Config) *Bot {
 	}
 }
 
-// Ready handler will be called when the session is ready.
+// Ready handler will be called when the session is ready. Guilds are set up as discord
+// sends their GuildCreate events.
 func (b *Bot) ReadyHandler(s *discordgo.Session, event *discordgo.Ready) {
 	// Set the playing status.
-	slog.Info("metro volleyball bot ready.")
+	slog.Info("metro volleyball bot ready.", "guilds", len(event.Guilds))
 
-	for _, guild := range event.Guilds {
-		channel, err := createChannelIfNotExists(s, guild.ID, b.config.UpdatesChannel)
+	if b.config.Commands == nil {
+		return
+	}
+
+	if _, err := b.SyncGlobalCommands(s, event.User.ID); err != nil {
+		slog.Error("sync global commands", "error", err)
+	}
+}
+
+// SyncGlobalCommands registers the commands globally with global commands on. Otherwise
+// the global commands left by a run with them on are removed, so they aren't listed
+// alongside each guild's copies.
+func (b *Bot) SyncGlobalCommands(s CommandSyncer, appID string) (CommandDiff, error) {
+	var desired []*discordgo.ApplicationCommand
+	if b.config.GlobalCommands {
+		desired = b.config.Commands.ApplicationCommands()
+	}
+
+	return SyncCommands(s, appID, "", desired)
+}
+
+// GuildCreateHandler sets up each guild when it becomes available, both at startup and
+// when the bot joins a guild later. The guild gets the updates channel and the commands.
+func (b *Bot) GuildCreateHandler(s *discordgo.Session, event *discordgo.GuildCreate) {
+	if event.Unavailable {
+		return
+	}
+
+	slog.Info("setting up guild", "guild_id", event.ID, "guild_name", event.Name)
+
+	sub, err := b.Subscription(event.ID)
+	if err != nil {
+		slog.Error("get guild subscription", "error", err, "guild_id", event.ID)
+	}
+
+	// guilds that chose a channel, or don't want updates, don't need the updates channel.
+	if err != nil || (sub.ChannelID == "" && !sub.Disabled) {
+		channel, err := createChannelIfNotExists(s, event.ID, b.config.UpdatesChannel)
 		if err != nil {
-			slog.Error("Could not create channel", "error", err, "guild_id", guild.ID)
-			continue
+			slog.Error("Could not create channel", "error", err, "guild_id", event.ID)
+		} else if flags.BotReadyMessage {
+			// send a ready message if the feature flag is enabled
+			s.ChannelMessageSend(channel.ID, fmt.Sprintf("metro bot ready, monitoring page: %s", b.config.MonitorUrl)) // add some emojis
 		}
+	}
 
-		// send a ready message if the feature flag is enabled
-		if flags.BotReadyMessage {
-			s.ChannelMessageSend(channel.ID, fmt.Sprintf("metro bot ready, monitoring page: %s", b.config.MonitorUrl)) // add some emojis
+	if b.config.Commands == nil {
+		return
+	}
+
+	// with global commands the guild's own copies are removed so they aren't listed twice.
+	var desired []*discordgo.ApplicationCommand
+	if !b.config.GlobalCommands {
+		desired = b.config.Commands.ApplicationCommands()
+	}
+
+	if _, err := SyncCommands(s, s.State.User.ID, event.ID, desired); err != nil {
+		slog.Error("sync guild commands", "error", err, "guild_id", event.ID)
+	}
+}
+
+// ChangeHandler sends the message to every guild following the topic.
+func (b *Bot) ChangeHandler(s *discordgo.Session, topic subscription.Topic, message string) ([]*discordgo.Message, []error) {
+	return b.Broadcast(s, topic, func(subscription.Subscription) Response {
+		return Text(message)
+	})
+}
+
+// Subscription returns what the guild follows, the default subscription when the guild
+// hasn't chosen.
+func (b *Bot) Subscription(guildID string) (subscription.Subscription, error) {
+	if b.config.Subscriptions != nil {
+		sub, found, err := b.config.Subscriptions.Get(guildID)
+		if err != nil {
+			return subscription.Subscription{}, fmt.Errorf("unable to get subscription: %w", err)
+		}
+
+		if found {
+			return sub, nil
+		}
+	}
+
+	sub := b.config.Default
+	sub.GuildID = guildID
+
+	return sub, nil
+}
+
+// Topics returns every topic a guild could be following, the default subscription's
+// topics and those of every saved subscription.
+func (b *Bot) Topics() ([]subscription.Topic, error) {
+	subscriptions := []subscription.Subscription{b.config.Default}
+
+	if b.config.Subscriptions != nil {
+		saved, err := b.config.Subscriptions.All()
+		if err != nil {
+			return nil, fmt.Errorf("unable to list subscriptions: %w", err)
 		}
+		subscriptions = append(subscriptions, saved...)
 	}
+
+	return subscription.Topics(subscriptions), nil
 }
 
-func (b *Bot) ChangeHandler(s *discordgo.Session, message string) ([]*discordgo.Message, []error) {
+// Broadcast sends a response to every guild following the topic, splitting it over
+// several messages when it's too large for one. The response is rendered for each guild's
+// subscription, so it can be tailored to the team the guild follows.
+func (b *Bot) Broadcast(s *discordgo.Session, topic subscription.Topic, render func(subscription.Subscription) Response) ([]*discordgo.Message, []error) {
 	// Get a list of all the guilds that are available for messages
 	guilds, err := s.UserGuilds(100, "", "")
 	if err != nil {
@@ -56,23 +162,40 @@ func (b *Bot) ChangeHandler(s *discordgo.Session, message string) ([]*discordgo.
 	var errors []error
 	var messages []*discordgo.Message
 
-	// Send a message to each guild
+	// Send the messages to each guild following the topic
 	for _, guild := range guilds {
-		channel, err := createChannelIfNotExists(s, guild.ID, b.config.UpdatesChannel)
+		sub, err := b.Subscription(guild.ID)
 		if err != nil {
-			errors = append(errors, fmt.Errorf("unable to create channel: %w, guild[%s]", err, guild.Name))
+			errors = append(errors, fmt.Errorf("%w, guild[%s]", err, guild.Name))
 			continue
 		}
 
-		message, err := s.ChannelMessageSend(channel.ID, message)
-		if err != nil {
-			// if the message fails to send, add the error to the list of errors and continue to the next guild
-			errors = append(errors, fmt.Errorf("unable to send message: %w, guild[%s], channel[%s]", err, guild.Name, channel.Name))
+		if !sub.Matches(topic) {
 			continue
 		}
 
-		// add the successful message to the list of messages to be returned
-		messages = append(messages, message)
+		channelID := sub.ChannelID
+		if channelID == "" {
+			channel, err := createChannelIfNotExists(s, guild.ID, b.config.UpdatesChannel)
+			if err != nil {
+				errors = append(errors, fmt.Errorf("unable to create channel: %w, guild[%s]", err, guild.Name))
+				continue
+			}
+			channelID = channel.ID
+		}
+
+		for _, send := range render(sub).Messages() {
+			message, err := s.ChannelMessageSendComplex(channelID, send)
+			if err != nil {
+				// if a message fails to send, skip the rest of the response for this guild
+				// so it isn't posted out of order.
+				errors = append(errors, fmt.Errorf("unable to send message: %w, guild[%s], channel[%s]", err, guild.Name, channelID))
+				break
+			}
+
+			// add the successful message to the list of messages to be returned
+			messages = append(messages, message)
+		}
 	}
 
 	return messages, errors
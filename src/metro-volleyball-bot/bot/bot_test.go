@@ -1,10 +1,13 @@
 package bot_test
 
 import (
+	"reflect"
 	"testing"
 	"time"
 
 	"github.com/brewinski/home-lab-server/src/metro-volleyball-bot/bot"
+	"github.com/brewinski/home-lab-server/src/metro-volleyball-bot/store"
+	"github.com/brewinski/home-lab-server/src/metro-volleyball-bot/subscription"
 	"github.com/bwmarrin/discordgo"
 )
 
@@ -66,3 +69,40 @@ func TestBot_ReadyHandler(t *testing.T) {
 		})
 	}
 }
+
+func TestBot_Subscription(t *testing.T) {
+	registry := subscription.NewRegistry(store.NewMemory(), "subscriptions")
+	registry.Subscribe(subscription.Subscription{GuildID: "g1", ChannelID: "c1", Competition: "vqmetro24s1", Division: "WD", Team: "Setters"})
+	registry.Unsubscribe("g2")
+
+	defaults := subscription.Subscription{Competition: "vqmetro24s1", Division: "MD", Team: "Aces"}
+	b := bot.New(bot.Config{UpdatesChannel: "updates", Subscriptions: registry, Default: defaults})
+
+	tests := []struct {
+		guildID string
+		want    subscription.Subscription
+	}{
+		{guildID: "g1", want: subscription.Subscription{GuildID: "g1", ChannelID: "c1", Competition: "vqmetro24s1", Division: "WD", Team: "Setters"}},
+		{guildID: "g2", want: subscription.Subscription{GuildID: "g2", Disabled: true}},
+		{guildID: "g3", want: subscription.Subscription{GuildID: "g3", Competition: "vqmetro24s1", Division: "MD", Team: "Aces"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.guildID, func(t *testing.T) {
+			got, err := b.Subscription(tt.guildID)
+			if err != nil || got != tt.want {
+				t.Errorf("Bot.Subscription() = %+v, %v, want %+v", got, err, tt.want)
+			}
+		})
+	}
+
+	topics, err := b.Topics()
+	want := []subscription.Topic{
+		{Competition: "vqmetro24s1", Division: "MD"},
+		{Competition: "vqmetro24s1", Division: "MD", Team: "Aces"},
+		{Competition: "vqmetro24s1", Division: "WD"},
+		{Competition: "vqmetro24s1", Division: "WD", Team: "Setters"},
+	}
+	if err != nil || !reflect.DeepEqual(topics, want) {
+		t.Errorf("Bot.Topics() = %v, %v, want %v", topics, err, want)
+	}
+}
@@ -6,101 +6,156 @@ import (
 	"github.com/bwmarrin/discordgo"
 )
 
-var (
-	commands = []*discordgo.ApplicationCommand{
-		{
-			Name: "vb-help",
-			// All commands and options must have a description
-			// Commands/options without description will fail the registration
-			// of the command.
-			Description: "help documentation.",
-		},
-		{
-			Name:        "vb-ladder",
-			Description: "view the latest ladder results.",
-			Version:     "1.0.0",
-		},
-		{
-			Name:        "vb-next-game",
-			Description: "view the next game for the team.",
-			Version:     "1.0.0",
-		},
+// Request is a command a user has run.
+type Request struct {
+	Command string
+	Options Options
+	// GuildID and ChannelID are where the command was run, GuildID is empty in direct
+	// messages.
+	GuildID   string
+	ChannelID string
+}
+
+// Interactions answers interactions, *discordgo.Session implements it.
+type Interactions interface {
+	InteractionRespond(interaction *discordgo.Interaction, resp *discordgo.InteractionResponse, options ...discordgo.RequestOption) error
+	InteractionResponseEdit(interaction *discordgo.Interaction, newresp *discordgo.WebhookEdit, options ...discordgo.RequestOption) (*discordgo.Message, error)
+	InteractionResponseDelete(interaction *discordgo.Interaction, options ...discordgo.RequestOption) error
+	FollowupMessageCreate(interaction *discordgo.Interaction, wait bool, data *discordgo.WebhookParams, options ...discordgo.RequestOption) (*discordgo.Message, error)
+}
+
+// OnCommandHandler handles all commands for the bot. and allows the user to register
+// a callback that returns the response to send to the channel. The callback receives the
+// command name, the options the user provided and where the command was run.
+func OnCommandHandlerFactory(callback func(Request) (Response, error)) func(*discordgo.Session, *discordgo.InteractionCreate) {
+	return func(s *discordgo.Session, i *discordgo.InteractionCreate) {
+		HandleCommand(s, i, callback)
 	}
-)
+}
 
-// RegisterCommands registers all commands for the bot.
-func RegisterCommands(s *discordgo.Session) error {
-	// Get a list of all the guilds that are available for messages
-	guilds, err := s.UserGuilds(100, "", "")
-	if err != nil {
-		return err
+// HandleCommand acknowledges the command straight away so discord waits for the callback
+// instead of giving up after 3 seconds, then replaces the acknowledgement with the
+// callback's response. Errors are only shown to the user who ran the command.
+func HandleCommand(s Interactions, i *discordgo.InteractionCreate, callback func(Request) (Response, error)) {
+	if i.Type != discordgo.InteractionApplicationCommand {
+		return
 	}
 
-	// register commands for each guild
-	for _, guild := range guilds {
-		for _, command := range commands {
-			_, err := s.ApplicationCommandCreate(s.State.User.ID, guild.ID, command)
-			if err != nil {
-				return err
-			}
-		}
+	data := i.ApplicationCommandData()
+	slog.Info("handling command", "command", data.Name, "guild_id", i.GuildID)
+
+	// discord shows the bot as thinking until the response is edited in.
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+	})
+	if err != nil {
+		slog.Error("acknowledge interaction", "error", err, "command", data.Name)
+		return
 	}
-	return nil
-}
 
-// RemoveCommands deregisters all commands for the bot.
-func RemoveCommands(s *discordgo.Session) error {
-	// Get a list of all the guilds that are available for messages
-	guilds, err := s.UserGuilds(100, "", "")
+	response, err := callback(Request{
+		Command:   data.Name,
+		Options:   ParseOptions(data.Options),
+		GuildID:   i.GuildID,
+		ChannelID: i.ChannelID,
+	})
 	if err != nil {
-		return err
+		slog.Error("error handling command", "error", err, "command", data.Name)
+		respondError(s, i, "something went wrong, please try again later")
+		return
 	}
 
-	// register commands for each guild
-	for _, guild := range guilds {
-		guildCommands, err := s.ApplicationCommands(s.State.User.ID, guild.ID)
+	respond(s, i, response)
+}
+
+// OnAutocompleteHandlerFactory handles autocomplete requests for command options. The
+// callback receives the command name, the options entered so far and the option being
+// typed in, and returns the choices to offer.
+func OnAutocompleteHandlerFactory(callback func(command string, options Options, focused *discordgo.ApplicationCommandInteractionDataOption) ([]*discordgo.ApplicationCommandOptionChoice, error)) func(*discordgo.Session, *discordgo.InteractionCreate) {
+	return func(s *discordgo.Session, i *discordgo.InteractionCreate) {
+		if i.Type != discordgo.InteractionApplicationCommandAutocomplete {
+			return
+		}
+
+		data := i.ApplicationCommandData()
+		options := ParseOptions(data.Options)
+
+		focused, ok := options.Focused()
+		if !ok {
+			return
+		}
+
+		choices, err := callback(data.Name, options, focused)
 		if err != nil {
-			return err
+			// an empty list tells discord there's nothing to suggest rather than leaving
+			// the user waiting.
+			slog.Error("error autocompleting option", "error", err, "command", data.Name, "option", focused.Name)
+			choices = nil
+		}
+
+		if choices == nil {
+			choices = []*discordgo.ApplicationCommandOptionChoice{}
 		}
 
-		for _, command := range guildCommands {
-			slog.Info("removing command", "command", command.Name)
-			err := s.ApplicationCommandDelete(s.State.User.ID, guild.ID, command.ID)
-			if err != nil {
-				return err
-			}
+		err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionApplicationCommandAutocompleteResult,
+			Data: &discordgo.InteractionResponseData{Choices: choices},
+		})
+		if err != nil {
+			slog.Error("respond to autocomplete", "error", err)
 		}
 	}
-	return nil
 }
 
-// OnCommandHandler handles all commands for the bot. and allows the user to register
-// a callback that returns the string to send to the channel.
-func OnCommandHandlerFactory(callback func(string) (string, error)) func(*discordgo.Session, *discordgo.InteractionCreate) {
-	return func(s *discordgo.Session, i *discordgo.InteractionCreate) {
-		slog.Info("handling command", "command", i.ApplicationCommandData().Name)
+// respond edits the first message of the response into the acknowledgement and sends the
+// rest as follow ups.
+func respond(s Interactions, i *discordgo.InteractionCreate, response Response) {
+	messages := response.Messages()
+	if len(messages) == 0 {
+		messages = Text("nothing to show").Messages()
+	}
 
-		message, err := callback(i.ApplicationCommandData().Name)
+	edit := discordgo.WebhookEdit{Files: messages[0].Files}
+	if messages[0].Content != "" {
+		edit.Content = &messages[0].Content
+	}
+
+	if len(messages[0].Embeds) > 0 {
+		edit.Embeds = &messages[0].Embeds
+	}
+
+	slog.Info("responding to interaction", "messages", len(messages))
+	if _, err := s.InteractionResponseEdit(i.Interaction, &edit); err != nil {
+		slog.Error("respond to interaction", "error", err)
+		return
+	}
+
+	for _, message := range messages[1:] {
+		_, err := s.FollowupMessageCreate(i.Interaction, true, &discordgo.WebhookParams{
+			Content: message.Content,
+			Embeds:  message.Embeds,
+			Files:   message.Files,
+		})
 		if err != nil {
-			slog.Error("error handling command", "error", err)
-			responseWithMessage(s, i, "something went wrong, please try again later")
+			slog.Error("send follow up message", "error", err)
 			return
 		}
-
-		responseWithMessage(s, i, message)
 	}
 }
 
-// responseWithMessage handles the help command.
-func responseWithMessage(s *discordgo.Session, i *discordgo.InteractionCreate, message string) {
-	// Create the response object
-	response := discordgo.InteractionResponse{
-		Type: discordgo.InteractionResponseChannelMessageWithSource,
-		Data: &discordgo.InteractionResponseData{
-			Content: message,
-		},
+// respondError removes the acknowledgement and tells the user who ran the command what
+// went wrong. The acknowledgement is visible to everyone so it can't be edited into an
+// ephemeral message.
+func respondError(s Interactions, i *discordgo.InteractionCreate, message string) {
+	if err := s.InteractionResponseDelete(i.Interaction); err != nil {
+		slog.Error("delete interaction response", "error", err)
+	}
+
+	_, err := s.FollowupMessageCreate(i.Interaction, true, &discordgo.WebhookParams{
+		Content: message,
+		Flags:   discordgo.MessageFlagsEphemeral,
+	})
+	if err != nil {
+		slog.Error("send error message", "error", err)
 	}
-	slog.Info("responding to interaction", "response", response)
-	// Respond to the interaction with the response object
-	s.InteractionRespond(i.Interaction, &response)
 }
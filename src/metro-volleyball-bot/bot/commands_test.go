@@ -0,0 +1,141 @@
+package bot_test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/brewinski/home-lab-server/src/metro-volleyball-bot/bot"
+	"github.com/bwmarrin/discordgo"
+)
+
+// fakeInteractions records the calls made to answer an interaction.
+type fakeInteractions struct {
+	calls      []string
+	respondErr error
+	responses  []*discordgo.InteractionResponse
+	edits      []*discordgo.WebhookEdit
+	followups  []*discordgo.WebhookParams
+}
+
+func (f *fakeInteractions) InteractionRespond(interaction *discordgo.Interaction, resp *discordgo.InteractionResponse, options ...discordgo.RequestOption) error {
+	f.calls = append(f.calls, "respond")
+	f.responses = append(f.responses, resp)
+	return f.respondErr
+}
+
+func (f *fakeInteractions) InteractionResponseEdit(interaction *discordgo.Interaction, newresp *discordgo.WebhookEdit, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	f.calls = append(f.calls, "edit")
+	f.edits = append(f.edits, newresp)
+	return &discordgo.Message{}, nil
+}
+
+func (f *fakeInteractions) InteractionResponseDelete(interaction *discordgo.Interaction, options ...discordgo.RequestOption) error {
+	f.calls = append(f.calls, "delete")
+	return nil
+}
+
+func (f *fakeInteractions) FollowupMessageCreate(interaction *discordgo.Interaction, wait bool, data *discordgo.WebhookParams, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	f.calls = append(f.calls, "followup")
+	f.followups = append(f.followups, data)
+	return &discordgo.Message{}, nil
+}
+
+func commandInteraction(name string) *discordgo.InteractionCreate {
+	return &discordgo.InteractionCreate{Interaction: &discordgo.Interaction{
+		Type:    discordgo.InteractionApplicationCommand,
+		GuildID: "g1",
+		Data:    discordgo.ApplicationCommandInteractionData{Name: name},
+	}}
+}
+
+func TestHandleCommand(t *testing.T) {
+	tests := []struct {
+		name       string
+		response   bot.Response
+		err        error
+		respondErr error
+		wantCalls  []string
+	}{
+		{
+			name:      "edit the response into the acknowledgement",
+			response:  bot.Text("pong"),
+			wantCalls: []string{"respond", "edit"},
+		},
+		{
+			name:      "send long responses as follow ups",
+			response:  bot.Text(strings.Repeat("line\n", 1000)),
+			wantCalls: []string{"respond", "edit", "followup", "followup"},
+		},
+		{
+			name:      "report errors to the user",
+			err:       errors.New("softr is down"),
+			wantCalls: []string{"respond", "delete", "followup"},
+		},
+		{
+			name:       "stop when the acknowledgement fails",
+			response:   bot.Text("pong"),
+			respondErr: errors.New("unknown interaction"),
+			wantCalls:  []string{"respond"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &fakeInteractions{respondErr: tt.respondErr}
+
+			var got bot.Request
+			bot.HandleCommand(s, commandInteraction("vb-ping"), func(req bot.Request) (bot.Response, error) {
+				got = req
+				return tt.response, tt.err
+			})
+
+			if !reflect.DeepEqual(s.calls, tt.wantCalls) {
+				t.Fatalf("HandleCommand() calls = %v, want %v", s.calls, tt.wantCalls)
+			}
+
+			if s.responses[0].Type != discordgo.InteractionResponseDeferredChannelMessageWithSource {
+				t.Errorf("HandleCommand() acknowledged with %v, want a deferred response", s.responses[0].Type)
+			}
+
+			if tt.respondErr == nil && (got.Command != "vb-ping" || got.GuildID != "g1") {
+				t.Errorf("HandleCommand() request = %+v, want the command and guild", got)
+			}
+		})
+	}
+}
+
+func TestHandleCommand_Messages(t *testing.T) {
+	s := &fakeInteractions{}
+	bot.HandleCommand(s, commandInteraction("vb-ping"), func(req bot.Request) (bot.Response, error) {
+		return bot.Text("pong"), nil
+	})
+
+	if len(s.edits) != 1 || s.edits[0].Content == nil || *s.edits[0].Content != "pong" || s.edits[0].Embeds != nil {
+		t.Errorf("HandleCommand() edits = %+v, want the content only", s.edits)
+	}
+
+	s = &fakeInteractions{}
+	bot.HandleCommand(s, commandInteraction("vb-ping"), func(req bot.Request) (bot.Response, error) {
+		return bot.Response{}, errors.New("softr is down")
+	})
+
+	if len(s.followups) != 1 || s.followups[0].Flags != discordgo.MessageFlagsEphemeral {
+		t.Errorf("HandleCommand() followups = %+v, want an ephemeral error", s.followups)
+	}
+}
+
+func TestHandleCommand_IgnoresOtherInteractions(t *testing.T) {
+	s := &fakeInteractions{}
+	i := commandInteraction("vb-ping")
+	i.Type = discordgo.InteractionApplicationCommandAutocomplete
+
+	bot.HandleCommand(s, i, func(req bot.Request) (bot.Response, error) {
+		t.Errorf("HandleCommand() called the callback for an autocomplete interaction")
+		return bot.Response{}, nil
+	})
+
+	if len(s.calls) != 0 {
+		t.Errorf("HandleCommand() calls = %v, want none", s.calls)
+	}
+}
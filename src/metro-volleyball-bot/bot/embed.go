@@ -0,0 +1,218 @@
+package bot
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/brewinski/home-lab-server/src/metro-volleyball-bot/vq"
+	"github.com/bwmarrin/discordgo"
+)
+
+// Embed colours.
+const (
+	colorLadder    = 0x1f6feb
+	colorHighlight = 0xf0b429
+)
+
+// team names longer than this are truncated so the table fits on a phone.
+const maxTeamColumn = 18
+
+// LadderEmbedOptions configure how the ladder is rendered.
+type LadderEmbedOptions struct {
+	Title string
+	// URL links the title to the ladder page. Optional.
+	URL string
+	// Highlight is the team the bot follows, its row is marked. Optional.
+	Highlight string
+	// View is one of the vq ladder views, defaults to the overall ranking. Divisions
+	// that aren't split into pools are always shown overall.
+	View string
+}
+
+// LadderEmbeds renders the standings as embeds holding an aligned table of rank, team,
+// W-L, sets and competition points. The table is split over as many embeds as needed to
+// stay within discord's limits. The pools view has a table for each pool titled with its
+// status, and the pool of each team is shown in the other views once the division is split into pools.
+func LadderEmbeds(standings []vq.Standing, opts LadderEmbedOptions) []*discordgo.MessageEmbed {
+	if len(standings) == 0 {
+		return []*discordgo.MessageEmbed{{Title: opts.Title, URL: opts.URL, Description: "no teams on the ladder yet", Color: colorLadder}}
+	}
+
+	teamWidth := len("Team")
+	for _, standing := range standings {
+		teamWidth = max(teamWidth, min(utf8.RuneCountInString(standing.Team), maxTeamColumn))
+	}
+
+	view := opts.View
+	if !vq.HasPools(standings) {
+		view = vq.ViewOverall
+	}
+
+	var embeds []*discordgo.MessageEmbed
+	switch view {
+	case vq.ViewPools:
+		for _, group := range vq.GroupByPool(standings) {
+			title := opts.Title + " · " + vq.PoolName(group.Pool)
+			if group.Status != "" {
+				title += " (" + group.Status + ")"
+			}
+			embeds = append(embeds, ladderTable(title, group.Standings, view, false, teamWidth, opts)...)
+		}
+	case vq.ViewCrossover:
+		embeds = ladderTable(opts.Title+" · Crossover", vq.CrossoverOrder(standings), view, true, teamWidth, opts)
+	default:
+		embeds = ladderTable(opts.Title, standings, vq.ViewOverall, vq.HasPools(standings), teamWidth, opts)
+	}
+
+	// the pools view has each pool's status in its title.
+	var footer []string
+	if status := vq.PoolStatus(standings); status != "" && view != vq.ViewPools {
+		footer = append(footer, status)
+	}
+	if opts.Highlight != "" {
+		footer = append(footer, "» "+opts.Highlight)
+	}
+	if len(footer) > 0 {
+		embeds[len(embeds)-1].Footer = &discordgo.MessageEmbedFooter{Text: strings.Join(footer, " · ")}
+	}
+
+	return embeds
+}
+
+// ladderTable renders one table of the ladder, ranking the teams for the view.
+func ladderTable(title string, standings []vq.Standing, view string, showPool bool, teamWidth int, opts LadderEmbedOptions) []*discordgo.MessageEmbed {
+	poolColumn := func(pool string) string {
+		if !showPool {
+			return ""
+		}
+
+		return fmt.Sprintf(" %-4s", pool)
+	}
+
+	header := fmt.Sprintf("  %3s %s%s %5s %5s %4s", "#", pad("Team", teamWidth), poolColumn("Pool"), "W-L", "Sets", "Pts")
+
+	rows := make([]string, 0, len(standings))
+	var highlighted []string
+	for _, standing := range standings {
+		marker := " "
+		if opts.Highlight != "" && strings.EqualFold(strings.TrimSpace(standing.Team), strings.TrimSpace(opts.Highlight)) {
+			marker = "»"
+		}
+
+		row := fmt.Sprintf("%s %3d %s%s %5s %5s %4s",
+			marker,
+			vq.ViewRank(standing, view),
+			pad(truncate(standing.Team, teamWidth), teamWidth),
+			poolColumn(truncate(standing.Pool, 4)),
+			winLoss(standing.Matches),
+			fmt.Sprintf("%d-%d", standing.Sets.For, standing.Sets.Against),
+			strconv.FormatFloat(standing.CompetitionPoints, 'f', -1, 64),
+		)
+		rows = append(rows, row)
+		if marker != " " {
+			highlighted = append(highlighted, row)
+		}
+	}
+
+	embeds := TableEmbeds(title, header, rows)
+	for _, embed := range embeds {
+		embed.URL = opts.URL
+		embed.Color = colorLadder
+
+		// colour the embed holding the followed team so it stands out when scrolling.
+		for _, row := range highlighted {
+			if strings.Contains(embed.Description, row) {
+				embed.Color = colorHighlight
+			}
+		}
+	}
+
+	return embeds
+}
+
+// TableEmbeds puts the rows in code blocks under the header, starting a new embed
+// whenever the description would go over discord's limit. Embeds after the first are
+// titled as continuations.
+func TableEmbeds(title string, header string, rows []string) []*discordgo.MessageEmbed {
+	const openFence, closeFence = "```\n", "\n```"
+
+	var embeds []*discordgo.MessageEmbed
+	current := strings.Builder{}
+
+	start := func() {
+		current.Reset()
+		current.WriteString(openFence)
+		current.WriteString(header)
+	}
+
+	finish := func() {
+		current.WriteString(closeFence)
+		embedTitle := title
+		if len(embeds) > 0 {
+			embedTitle = title + " (cont.)"
+		}
+		embeds = append(embeds, &discordgo.MessageEmbed{Title: embedTitle, Description: current.String()})
+	}
+
+	start()
+	for i, row := range rows {
+		if i > 0 && utf8.RuneCountInString(current.String())+1+utf8.RuneCountInString(row)+len(closeFence) > maxEmbedDescription {
+			finish()
+			start()
+		}
+
+		current.WriteString("\n")
+		current.WriteString(row)
+	}
+	finish()
+
+	return embeds
+}
+
+// TextEmbeds puts text in as many embeds as needed to stay within discord's description
+// limit. Embeds after the first are titled as continuations.
+func TextEmbeds(title string, text string, color int) []*discordgo.MessageEmbed {
+	var embeds []*discordgo.MessageEmbed
+	for i, description := range SplitContent(text, maxEmbedDescription) {
+		embedTitle := title
+		if i > 0 {
+			embedTitle = title + " (cont.)"
+		}
+		embeds = append(embeds, &discordgo.MessageEmbed{Title: embedTitle, Description: description, Color: color})
+	}
+
+	return embeds
+}
+
+// LadderUpdateResponse is the notification sent when the ladder changes, a summary of the
+// changes followed by the new ladder.
+func LadderUpdateResponse(diff vq.LadderDiff, standings []vq.Standing, opts LadderEmbedOptions) Response {
+	embeds := TextEmbeds("Ladder update", diff.String(), colorHighlight)
+	embeds = append(embeds, LadderEmbeds(standings, opts)...)
+
+	return Response{Embeds: embeds}
+}
+
+// winLoss formats the record as W-L, with draws only shown when there are some.
+func winLoss(r vq.Record) string {
+	if r.Drawn > 0 {
+		return r.String()
+	}
+
+	return fmt.Sprintf("%d-%d", r.Won, r.Lost)
+}
+
+func pad(s string, width int) string {
+	return s + strings.Repeat(" ", max(0, width-utf8.RuneCountInString(s)))
+}
+
+func truncate(s string, width int) string {
+	runes := []rune(s)
+	if len(runes) <= width {
+		return s
+	}
+
+	return string(runes[:width-1]) + "…"
+}
@@ -0,0 +1,165 @@
+package bot_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/brewinski/home-lab-server/src/metro-volleyball-bot/bot"
+	"github.com/brewinski/home-lab-server/src/metro-volleyball-bot/vq"
+)
+
+func TestLadderEmbeds(t *testing.T) {
+	standings := []vq.Standing{
+		{Rank: 1, Team: "Net Results", Matches: vq.Record{Won: 3}, Sets: vq.Tally{For: 9, Against: 1}, CompetitionPoints: 18},
+		{Rank: 2, Team: "Aces", Matches: vq.Record{Won: 2, Lost: 1}, Sets: vq.Tally{For: 6, Against: 4}, CompetitionPoints: 12.5},
+		{Rank: 3, Team: "The Extremely Long Team Name", Matches: vq.Record{Won: 0, Lost: 2, Drawn: 1}, Sets: vq.Tally{For: 2, Against: 7}, CompetitionPoints: 3},
+	}
+
+	embeds := bot.LadderEmbeds(standings, bot.LadderEmbedOptions{Title: "Ladder MD", URL: "https://example.com/ladder", Highlight: "aces"})
+	if len(embeds) != 1 {
+		t.Fatalf("LadderEmbeds() = %d embeds, want 1", len(embeds))
+	}
+
+	embed := embeds[0]
+	if embed.Title != "Ladder MD" || embed.URL != "https://example.com/ladder" || embed.Footer == nil {
+		t.Errorf("LadderEmbeds() = %+v, want the title, url and a footer for the followed team", embed)
+	}
+
+	lines := strings.Split(embed.Description, "\n")
+	want := []string{
+		"```",
+		"    # Team                 W-L  Sets  Pts",
+		"    1 Net Results          3-0   9-1   18",
+		"»   2 Aces                 2-1   6-4 12.5",
+		"    3 The Extremely Lon… 0-2-1   2-7    3",
+		"```",
+	}
+	if strings.Join(lines, "\n") != strings.Join(want, "\n") {
+		t.Errorf("LadderEmbeds() description =\n%s\nwant\n%s", embed.Description, strings.Join(want, "\n"))
+	}
+}
+
+func TestLadderEmbeds_Split(t *testing.T) {
+	var standings []vq.Standing
+	for i := 1; i <= 150; i++ {
+		standings = append(standings, vq.Standing{Rank: i, Team: fmt.Sprintf("Team %d", i)})
+	}
+
+	embeds := bot.LadderEmbeds(standings, bot.LadderEmbedOptions{Title: "Ladder", Highlight: "Team 150"})
+	if len(embeds) < 2 {
+		t.Fatalf("LadderEmbeds() = %d embeds, want the ladder split", len(embeds))
+	}
+
+	rows := 0
+	for i, embed := range embeds {
+		if utf8.RuneCountInString(embed.Description) > 4096 {
+			t.Errorf("LadderEmbeds()[%d] description is %d characters", i, utf8.RuneCountInString(embed.Description))
+		}
+		if !strings.HasPrefix(embed.Description, "```\n    # Team") || !strings.HasSuffix(embed.Description, "\n```") {
+			t.Errorf("LadderEmbeds()[%d] should repeat the header in its own code block", i)
+		}
+		rows += strings.Count(embed.Description, "\n") - 2
+	}
+
+	if rows != len(standings) {
+		t.Errorf("LadderEmbeds() rendered %d rows, want %d", rows, len(standings))
+	}
+
+	if last := embeds[len(embeds)-1]; last.Color == embeds[0].Color || !strings.HasSuffix(last.Title, "(cont.)") {
+		t.Errorf("LadderEmbeds() last embed = %+v, want it highlighted as a continuation", last)
+	}
+}
+
+func TestLadderUpdateResponse(t *testing.T) {
+	old := []vq.Standing{{Rank: 1, Team: "Aces"}, {Rank: 2, Team: "APG"}}
+	new := []vq.Standing{{Rank: 1, Team: "APG", CompetitionPoints: 4}, {Rank: 2, Team: "Aces"}}
+
+	response := bot.LadderUpdateResponse(vq.DiffLadder(old, new), new, bot.LadderEmbedOptions{Title: "Ladder"})
+	if len(response.Embeds) != 2 {
+		t.Fatalf("LadderUpdateResponse() = %d embeds, want the changes and the ladder", len(response.Embeds))
+	}
+
+	if !strings.Contains(response.Embeds[0].Description, "APG ↑1 to 1st (+4 pts)") {
+		t.Errorf("LadderUpdateResponse() changes = %q", response.Embeds[0].Description)
+	}
+}
+
+func TestLadderEmbeds_Views(t *testing.T) {
+	standings := []vq.Standing{
+		{Rank: 1, Team: "Net Results", Pool: "A", PoolStatus: "Pool Play", PositionalRanking: 1, CrossoverRanking: 1, CompetitionPoints: 13},
+		{Rank: 2, Team: "Dig Deep", Pool: "B", PoolStatus: "Finals", PositionalRanking: 1, CrossoverRanking: 3, CompetitionPoints: 11},
+		{Rank: 3, Team: "Aces", Pool: "A", PoolStatus: "Pool Play", PositionalRanking: 2, CrossoverRanking: 2, CompetitionPoints: 5},
+	}
+
+	tests := []struct {
+		name       string
+		view       string
+		wantTitles []string
+		wantRows   [][]string
+		wantFooter string
+	}{
+		{
+			name:       "overall shows the pool",
+			view:       "",
+			wantTitles: []string{"Ladder MD"},
+			wantFooter: "Pool Play, Finals · » Aces",
+			wantRows: [][]string{{
+				"    1 Net Results A      0-0   0-0   13",
+				"    2 Dig Deep    B      0-0   0-0   11",
+				"»   3 Aces        A      0-0   0-0    5",
+			}},
+		},
+		{
+			name:       "pools",
+			view:       vq.ViewPools,
+			wantTitles: []string{"Ladder MD · Pool A (Pool Play)", "Ladder MD · Pool B (Finals)"},
+			wantFooter: "» Aces",
+			wantRows: [][]string{
+				{
+					"    1 Net Results   0-0   0-0   13",
+					"»   2 Aces          0-0   0-0    5",
+				},
+				{
+					"    1 Dig Deep      0-0   0-0   11",
+				},
+			},
+		},
+		{
+			name:       "crossover",
+			view:       vq.ViewCrossover,
+			wantTitles: []string{"Ladder MD · Crossover"},
+			wantFooter: "Pool Play, Finals · » Aces",
+			wantRows: [][]string{{
+				"    1 Net Results A      0-0   0-0   13",
+				"»   2 Aces        A      0-0   0-0    5",
+				"    3 Dig Deep    B      0-0   0-0   11",
+			}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			embeds := bot.LadderEmbeds(standings, bot.LadderEmbedOptions{Title: "Ladder MD", Highlight: "Aces", View: tt.view})
+			if len(embeds) != len(tt.wantTitles) {
+				t.Fatalf("LadderEmbeds() = %d embeds, want %d", len(embeds), len(tt.wantTitles))
+			}
+
+			for i, embed := range embeds {
+				if embed.Title != tt.wantTitles[i] {
+					t.Errorf("LadderEmbeds()[%d] title = %q, want %q", i, embed.Title, tt.wantTitles[i])
+				}
+
+				lines := strings.Split(embed.Description, "\n")
+				rows := lines[2 : len(lines)-1]
+				if strings.Join(rows, "\n") != strings.Join(tt.wantRows[i], "\n") {
+					t.Errorf("LadderEmbeds()[%d] rows =\n%s\nwant\n%s", i, strings.Join(rows, "\n"), strings.Join(tt.wantRows[i], "\n"))
+				}
+			}
+
+			if footer := embeds[len(embeds)-1].Footer; footer == nil || footer.Text != tt.wantFooter {
+				t.Errorf("LadderEmbeds() footer = %+v, want %q", footer, tt.wantFooter)
+			}
+		})
+	}
+}
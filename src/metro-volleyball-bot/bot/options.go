@@ -0,0 +1,111 @@
+package bot
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// discord shows at most this many autocomplete choices, and choice names are limited to
+// 100 characters.
+const (
+	maxChoices          = 25
+	maxChoiceNameLength = 100
+)
+
+// Options are the options the user provided with a command, keyed by name.
+type Options map[string]*discordgo.ApplicationCommandInteractionDataOption
+
+// ParseOptions indexes the options by name, options of sub commands are included.
+func ParseOptions(options []*discordgo.ApplicationCommandInteractionDataOption) Options {
+	parsed := Options{}
+	for _, option := range options {
+		parsed[option.Name] = option
+		for name, nested := range ParseOptions(option.Options) {
+			parsed[name] = nested
+		}
+	}
+
+	return parsed
+}
+
+// String returns the value of the named string option, or fallback if the user didn't
+// provide it or left it blank.
+func (o Options) String(name string, fallback string) string {
+	option, ok := o[name]
+	if !ok || option.Type != discordgo.ApplicationCommandOptionString {
+		return fallback
+	}
+
+	if value := strings.TrimSpace(option.StringValue()); value != "" {
+		return value
+	}
+
+	return fallback
+}
+
+// Channel returns the id of the named channel option, or fallback if the user didn't
+// provide it.
+func (o Options) Channel(name string, fallback string) string {
+	option, ok := o[name]
+	if !ok || option.Type != discordgo.ApplicationCommandOptionChannel {
+		return fallback
+	}
+
+	if id, ok := option.Value.(string); ok && id != "" {
+		return id
+	}
+
+	return fallback
+}
+
+// Focused returns the option the user is typing in while autocompleting.
+func (o Options) Focused() (*discordgo.ApplicationCommandInteractionDataOption, bool) {
+	for _, option := range o {
+		if option.Focused {
+			return option, true
+		}
+	}
+
+	return nil, false
+}
+
+// Choices offers the values matching what the user has typed so far, case insensitively.
+// Values starting with the typed text are listed first, then those containing it, each in
+// alphabetical order. At most 25 choices are returned.
+func Choices(values []string, typed string) []*discordgo.ApplicationCommandOptionChoice {
+	typed = strings.ToLower(strings.TrimSpace(typed))
+
+	var prefixed, contained []string
+	seen := map[string]bool{}
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		lower := strings.ToLower(value)
+		if value == "" || seen[lower] {
+			continue
+		}
+		seen[lower] = true
+
+		switch {
+		case strings.HasPrefix(lower, typed):
+			prefixed = append(prefixed, value)
+		case strings.Contains(lower, typed):
+			contained = append(contained, value)
+		}
+	}
+
+	sort.Slice(prefixed, func(i, j int) bool { return strings.ToLower(prefixed[i]) < strings.ToLower(prefixed[j]) })
+	sort.Slice(contained, func(i, j int) bool { return strings.ToLower(contained[i]) < strings.ToLower(contained[j]) })
+
+	choices := []*discordgo.ApplicationCommandOptionChoice{}
+	for _, value := range append(prefixed, contained...) {
+		if len(choices) == maxChoices {
+			break
+		}
+
+		choices = append(choices, &discordgo.ApplicationCommandOptionChoice{Name: truncate(value, maxChoiceNameLength), Value: value})
+	}
+
+	return choices
+}
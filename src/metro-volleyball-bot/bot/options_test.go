@@ -0,0 +1,79 @@
+package bot_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/brewinski/home-lab-server/src/metro-volleyball-bot/bot"
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestOptions_String(t *testing.T) {
+	options := bot.ParseOptions([]*discordgo.ApplicationCommandInteractionDataOption{
+		{Name: "team", Type: discordgo.ApplicationCommandOptionString, Value: "Aces"},
+		{Name: "blank", Type: discordgo.ApplicationCommandOptionString, Value: "  "},
+		{Name: "count", Type: discordgo.ApplicationCommandOptionInteger, Value: float64(3)},
+		{Name: "group", Type: discordgo.ApplicationCommandOptionSubCommand, Options: []*discordgo.ApplicationCommandInteractionDataOption{
+			{Name: "division", Type: discordgo.ApplicationCommandOptionString, Value: "WD"},
+		}},
+	})
+
+	tests := []struct {
+		name   string
+		option string
+		want   string
+	}{
+		{name: "provided", option: "team", want: "Aces"},
+		{name: "blank", option: "blank", want: "fallback"},
+		{name: "missing", option: "missing", want: "fallback"},
+		{name: "not a string", option: "count", want: "fallback"},
+		{name: "sub command option", option: "division", want: "WD"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := options.String(tt.option, "fallback"); got != tt.want {
+				t.Errorf("Options.String() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, ok := options.Focused(); ok {
+		t.Errorf("Options.Focused() = true, want false when no option is focused")
+	}
+}
+
+func TestChoices(t *testing.T) {
+	teams := []string{"Net Results", "Aces", "Block Party", "APG", "Blockers", "aces", ""}
+
+	tests := []struct {
+		name  string
+		typed string
+		want  []string
+	}{
+		{name: "everything when nothing is typed", typed: "", want: []string{"Aces", "APG", "Block Party", "Blockers", "Net Results"}},
+		{name: "prefix before contains", typed: "bl", want: []string{"Block Party", "Blockers"}},
+		{name: "contains", typed: "es", want: []string{"Aces", "Net Results"}},
+		{name: "case insensitive", typed: "  NET ", want: []string{"Net Results"}},
+		{name: "no matches", typed: "xyz", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, choice := range bot.Choices(teams, tt.typed) {
+				got = append(got, choice.Name)
+			}
+
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("Choices() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	var many []string
+	for i := 0; i < 40; i++ {
+		many = append(many, strings.Repeat("x", i+1))
+	}
+	if got := bot.Choices(many, "x"); len(got) != 25 {
+		t.Errorf("Choices() = %d choices, want at most 25", len(got))
+	}
+}
@@ -0,0 +1,157 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// HelpCommand is added to every registry, its reply is generated from the other commands.
+const HelpCommand = "vb-help"
+
+// Handler answers a command. ctx is cancelled when discord stops waiting for the answer.
+type Handler func(ctx context.Context, req Request) (Response, error)
+
+// Command is a slash command, everything discord needs to show it and what it does.
+type Command struct {
+	Name string
+	// Description is shown by discord as the command is typed, at most 100 characters.
+	// All commands and options must have a description, registration fails without one.
+	Description string
+	Options     []*discordgo.ApplicationCommandOption
+	// DefaultMemberPermissions limits who can run the command, nil lets everyone.
+	DefaultMemberPermissions *int64
+	// Help is the longer explanation shown by the help command. Optional.
+	Help    string
+	Handler Handler
+}
+
+// ApplicationCommand is the command's definition for registering with discord.
+func (c Command) ApplicationCommand() *discordgo.ApplicationCommand {
+	return &discordgo.ApplicationCommand{
+		Name:                     c.Name,
+		Description:              c.Description,
+		Version:                  "1.0.0",
+		Options:                  c.Options,
+		DefaultMemberPermissions: c.DefaultMemberPermissions,
+	}
+}
+
+// usage formats how the command is run, required options in angle brackets and optional
+// ones in square brackets, e.g. "/vb-h2h <team_b> [team_a]".
+func (c Command) usage() string {
+	sb := strings.Builder{}
+	sb.WriteString("/" + c.Name)
+	for _, option := range c.Options {
+		if option.Required {
+			sb.WriteString(" <" + option.Name + ">")
+		} else {
+			sb.WriteString(" [" + option.Name + "]")
+		}
+	}
+
+	return sb.String()
+}
+
+// Registry holds the bot's commands in the order they were added. It isn't safe to add
+// commands while handling them, build the registry before connecting.
+type Registry struct {
+	commands []Command
+	byName   map[string]int
+}
+
+// NewRegistry returns a registry with the help command.
+func NewRegistry() *Registry {
+	r := &Registry{byName: map[string]int{}}
+	r.commands = append(r.commands, Command{
+		Name:        HelpCommand,
+		Description: "help documentation.",
+		Help:        "lists the commands and their options.",
+		Handler: func(ctx context.Context, req Request) (Response, error) {
+			return r.Help(), nil
+		},
+	})
+	r.byName[HelpCommand] = 0
+
+	return r
+}
+
+// Add registers the commands. It fails when a command is missing its name, description
+// or handler, or has the same name as a command already registered.
+func (r *Registry) Add(commands ...Command) error {
+	for _, command := range commands {
+		switch {
+		case command.Name == "":
+			return fmt.Errorf("Registry.Add() command has no name")
+		case command.Description == "":
+			return fmt.Errorf("Registry.Add() command %s has no description", command.Name)
+		case command.Handler == nil:
+			return fmt.Errorf("Registry.Add() command %s has no handler", command.Name)
+		}
+
+		if _, ok := r.byName[command.Name]; ok {
+			return fmt.Errorf("Registry.Add() command %s is already registered", command.Name)
+		}
+
+		r.byName[command.Name] = len(r.commands)
+		r.commands = append(r.commands, command)
+	}
+
+	return nil
+}
+
+// Lookup returns the named command.
+func (r *Registry) Lookup(name string) (Command, bool) {
+	i, ok := r.byName[name]
+	if !ok {
+		return Command{}, false
+	}
+
+	return r.commands[i], true
+}
+
+// Commands returns the registered commands in the order they were added.
+func (r *Registry) Commands() []Command {
+	return append([]Command(nil), r.commands...)
+}
+
+// ApplicationCommands returns the definitions of every registered command.
+func (r *Registry) ApplicationCommands() []*discordgo.ApplicationCommand {
+	definitions := make([]*discordgo.ApplicationCommand, 0, len(r.commands))
+	for _, command := range r.commands {
+		definitions = append(definitions, command.ApplicationCommand())
+	}
+
+	return definitions
+}
+
+// Handle runs the request's command.
+func (r *Registry) Handle(ctx context.Context, req Request) (Response, error) {
+	command, ok := r.Lookup(req.Command)
+	if !ok {
+		return Text("no action registered for this command: " + req.Command), nil
+	}
+
+	return command.Handler(ctx, req)
+}
+
+// Help lists every command with its usage, description and help.
+func (r *Registry) Help() Response {
+	sb := strings.Builder{}
+	for _, command := range r.commands {
+		sb.WriteString(fmt.Sprintf("**%s**\n%s\n", command.usage(), command.Description))
+		if command.Help != "" {
+			sb.WriteString(command.Help + "\n")
+		}
+
+		for _, option := range command.Options {
+			sb.WriteString(fmt.Sprintf("- `%s` %s\n", option.Name, option.Description))
+		}
+
+		sb.WriteString("\n")
+	}
+
+	return Response{Embeds: TextEmbeds("Volleybot commands", strings.TrimSpace(sb.String()), colorLadder)}
+}
@@ -0,0 +1,120 @@
+package bot_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/brewinski/home-lab-server/src/metro-volleyball-bot/bot"
+	"github.com/bwmarrin/discordgo"
+)
+
+func echo(reply string) bot.Handler {
+	return func(ctx context.Context, req bot.Request) (bot.Response, error) {
+		return bot.Text(reply), nil
+	}
+}
+
+func TestRegistry_Add(t *testing.T) {
+	tests := []struct {
+		name    string
+		command bot.Command
+		wantErr bool
+	}{
+		{name: "valid", command: bot.Command{Name: "vb-ping", Description: "ping.", Handler: echo("pong")}},
+		{name: "no name", command: bot.Command{Description: "ping.", Handler: echo("pong")}, wantErr: true},
+		{name: "no description", command: bot.Command{Name: "vb-ping", Handler: echo("pong")}, wantErr: true},
+		{name: "no handler", command: bot.Command{Name: "vb-ping", Description: "ping."}, wantErr: true},
+		{name: "duplicate", command: bot.Command{Name: bot.HelpCommand, Description: "help.", Handler: echo("help")}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := bot.NewRegistry().Add(tt.command)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Registry.Add() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRegistry_Handle(t *testing.T) {
+	registry := bot.NewRegistry()
+	err := registry.Add(bot.Command{Name: "vb-ping", Description: "ping.", Handler: echo("pong")})
+	if err != nil {
+		t.Fatalf("Registry.Add() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		command string
+		want    string
+	}{
+		{name: "registered", command: "vb-ping", want: "pong"},
+		{name: "unknown", command: "vb-missing", want: "no action registered for this command: vb-missing"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := registry.Handle(context.Background(), bot.Request{Command: tt.command})
+			if err != nil || got.Content != tt.want {
+				t.Errorf("Registry.Handle() = %q, %v, want %q", got.Content, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegistry_ApplicationCommands(t *testing.T) {
+	registry := bot.NewRegistry()
+	err := registry.Add(
+		bot.Command{Name: "vb-ping", Description: "ping.", Handler: echo("pong")},
+		bot.Command{Name: "vb-echo", Description: "echo.", Handler: echo("echo")},
+	)
+	if err != nil {
+		t.Fatalf("Registry.Add() error = %v", err)
+	}
+
+	var got []string
+	for _, command := range registry.ApplicationCommands() {
+		got = append(got, command.Name)
+	}
+
+	want := []string{bot.HelpCommand, "vb-ping", "vb-echo"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("Registry.ApplicationCommands() = %v, want %v", got, want)
+	}
+}
+
+func TestRegistry_Help(t *testing.T) {
+	registry := bot.NewRegistry()
+	err := registry.Add(bot.Command{
+		Name:        "vb-h2h",
+		Description: "compare two teams.",
+		Help:        "shows every game they've played.",
+		Options: []*discordgo.ApplicationCommandOption{
+			{Type: discordgo.ApplicationCommandOptionString, Name: "team_b", Description: "the other team.", Required: true},
+			{Type: discordgo.ApplicationCommandOptionString, Name: "team_a", Description: "the first team."},
+		},
+		Handler: echo("h2h"),
+	})
+	if err != nil {
+		t.Fatalf("Registry.Add() error = %v", err)
+	}
+
+	// the help command answers with the generated help.
+	response, err := registry.Handle(context.Background(), bot.Request{Command: bot.HelpCommand})
+	if err != nil || len(response.Embeds) == 0 {
+		t.Fatalf("Registry.Handle() = %v, %v, want the help embeds", response, err)
+	}
+
+	got := response.Embeds[0].Description
+	for _, want := range []string{
+		"**/vb-help**",
+		"**/vb-h2h <team_b> [team_a]**",
+		"compare two teams.",
+		"shows every game they've played.",
+		"- `team_b` the other team.",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Registry.Help() = %q, want it to contain %q", got, want)
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package bot
+
+import (
+	"bytes"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Discord message limits, lengths are in characters.
+const (
+	maxContentLength     = 2000
+	maxEmbedDescription  = 4096
+	maxEmbedsPerMessage  = 10
+	maxEmbedsTotalLength = 6000
+	maxFilesPerMessage   = 10
+)
+
+// Response is a message sent in reply to a command or as a notification. Responses that
+// are too large for one discord message are split over several by Messages.
+type Response struct {
+	Content string
+	Embeds  []*discordgo.MessageEmbed
+	Files   []Attachment
+}
+
+// Attachment is a file uploaded with a response. The data is kept rather than a reader so
+// the same response can be sent to several channels.
+type Attachment struct {
+	Name        string
+	ContentType string
+	Data        []byte
+}
+
+// Text returns a response with only text content.
+func Text(content string) Response {
+	return Response{Content: content}
+}
+
+// Messages splits the response into messages discord will accept. Long content is split
+// on line breaks, keeping code blocks closed in each message, and embeds are grouped so
+// each message stays within the embed limits. The first group of embeds is sent with the
+// last of the content, and files are attached to the last message.
+func (r Response) Messages() []*discordgo.MessageSend {
+	var messages []*discordgo.MessageSend
+	for _, content := range SplitContent(r.Content, maxContentLength) {
+		messages = append(messages, &discordgo.MessageSend{Content: content})
+	}
+
+	for i, embeds := range groupEmbeds(r.Embeds) {
+		if i == 0 && len(messages) > 0 {
+			messages[len(messages)-1].Embeds = embeds
+			continue
+		}
+		messages = append(messages, &discordgo.MessageSend{Embeds: embeds})
+	}
+
+	for start := 0; start < len(r.Files); start += maxFilesPerMessage {
+		if len(messages) == 0 || len(messages[len(messages)-1].Files) == maxFilesPerMessage {
+			messages = append(messages, &discordgo.MessageSend{})
+		}
+
+		last := messages[len(messages)-1]
+		for _, file := range r.Files[start:min(start+maxFilesPerMessage, len(r.Files))] {
+			last.Files = append(last.Files, &discordgo.File{
+				Name:        file.Name,
+				ContentType: file.ContentType,
+				Reader:      bytes.NewReader(file.Data),
+			})
+		}
+	}
+
+	return messages
+}
+
+// SplitContent splits content into chunks of at most limit characters. Chunks are split
+// between lines where possible, and a code block left open at the end of a chunk is closed
+// and reopened in the next one.
+func SplitContent(content string, limit int) []string {
+	if content == "" {
+		return nil
+	}
+
+	if utf8.RuneCountInString(content) <= limit {
+		return []string{content}
+	}
+
+	const fence = "```"
+
+	var chunks []string
+	current := strings.Builder{}
+	currentLength := 0
+	inCode := false
+
+	flush := func() {
+		chunk := current.String()
+		if inCode {
+			chunk += "\n" + fence
+		}
+		chunks = append(chunks, chunk)
+
+		current.Reset()
+		currentLength = 0
+		if inCode {
+			// the line break is added with the next line.
+			current.WriteString(fence)
+			currentLength = len(fence)
+		}
+	}
+
+	// lines are split so they always fit between a reopened and a closing fence.
+	for _, line := range splitLines(strings.Split(content, "\n"), limit-2*(len(fence)+1)) {
+		lineLength := utf8.RuneCountInString(line)
+
+		// leave room to close the code block if the chunk ends inside one.
+		closing := 0
+		if inCode != (strings.Count(line, fence)%2 == 1) {
+			closing = len(fence) + 1
+		}
+
+		if currentLength > 0 && currentLength+1+lineLength+closing > limit {
+			flush()
+		}
+
+		if currentLength > 0 {
+			current.WriteString("\n")
+			currentLength++
+		}
+		current.WriteString(line)
+		currentLength += lineLength
+
+		if strings.Count(line, fence)%2 == 1 {
+			inCode = !inCode
+		}
+	}
+
+	if currentLength > 0 {
+		chunks = append(chunks, current.String())
+	}
+
+	return chunks
+}
+
+// splitLines breaks lines longer than limit characters into several lines.
+func splitLines(lines []string, limit int) []string {
+	var split []string
+	for _, line := range lines {
+		runes := []rune(line)
+		for len(runes) > limit {
+			split = append(split, string(runes[:limit]))
+			runes = runes[limit:]
+		}
+		split = append(split, string(runes))
+	}
+
+	return split
+}
+
+// groupEmbeds groups embeds into messages without going over the number of embeds or
+// total embed length allowed in a message.
+func groupEmbeds(embeds []*discordgo.MessageEmbed) [][]*discordgo.MessageEmbed {
+	var groups [][]*discordgo.MessageEmbed
+	var current []*discordgo.MessageEmbed
+	currentLength := 0
+
+	for _, embed := range embeds {
+		length := embedLength(embed)
+		if len(current) > 0 && (len(current) == maxEmbedsPerMessage || currentLength+length > maxEmbedsTotalLength) {
+			groups = append(groups, current)
+			current = nil
+			currentLength = 0
+		}
+
+		current = append(current, embed)
+		currentLength += length
+	}
+
+	if len(current) > 0 {
+		groups = append(groups, current)
+	}
+
+	return groups
+}
+
+// embedLength counts the characters discord includes in the embed length limit.
+func embedLength(embed *discordgo.MessageEmbed) int {
+	length := utf8.RuneCountInString(embed.Title) + utf8.RuneCountInString(embed.Description)
+
+	if embed.Footer != nil {
+		length += utf8.RuneCountInString(embed.Footer.Text)
+	}
+
+	if embed.Author != nil {
+		length += utf8.RuneCountInString(embed.Author.Name)
+	}
+
+	for _, field := range embed.Fields {
+		length += utf8.RuneCountInString(field.Name) + utf8.RuneCountInString(field.Value)
+	}
+
+	return length
+}
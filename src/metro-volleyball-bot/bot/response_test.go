@@ -0,0 +1,157 @@
+package bot_test
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/brewinski/home-lab-server/src/metro-volleyball-bot/bot"
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestSplitContent(t *testing.T) {
+	var rows []string
+	for i := 0; i < 30; i++ {
+		rows = append(rows, fmt.Sprintf("row %02d", i))
+	}
+
+	tests := []struct {
+		name    string
+		content string
+		limit   int
+		want    []string
+	}{
+		{name: "empty", content: "", limit: 20, want: nil},
+		{name: "fits", content: "short", limit: 20, want: []string{"short"}},
+		{
+			name:    "split between lines",
+			content: "first line\nsecond line\nthird",
+			limit:   20,
+			want:    []string{"first line", "second line\nthird"},
+		},
+		{
+			name:    "close and reopen code blocks",
+			content: "Ladder:\n```\nrow 1\nrow 2\nrow 3\n```",
+			limit:   22,
+			want:    []string{"Ladder:\n```\nrow 1\n```", "```\nrow 2\nrow 3\n```"},
+		},
+		{
+			name:    "break long lines",
+			content: strings.Repeat("a", 25),
+			limit:   20,
+			want:    []string{strings.Repeat("a", 12), strings.Repeat("a", 12) + "\na"},
+		},
+		{
+			name:    "many lines",
+			content: "```\n" + strings.Join(rows, "\n") + "\n```",
+			limit:   100,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := bot.SplitContent(tt.content, tt.limit)
+
+			for _, chunk := range got {
+				if utf8.RuneCountInString(chunk) > tt.limit {
+					t.Errorf("SplitContent() chunk %q is longer than %d", chunk, tt.limit)
+				}
+				if strings.Count(chunk, "```")%2 != 0 {
+					t.Errorf("SplitContent() chunk %q leaves a code block open", chunk)
+				}
+			}
+
+			if tt.want != nil && strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("SplitContent() = %q, want %q", got, tt.want)
+			}
+
+			// nothing is lost.
+			joined := strings.Join(got, "\n")
+			for _, row := range rows {
+				if strings.Contains(tt.content, row) && !strings.Contains(joined, row) {
+					t.Errorf("SplitContent() lost %q", row)
+				}
+			}
+		})
+	}
+}
+
+func TestResponse_Messages(t *testing.T) {
+	embeds := make([]*discordgo.MessageEmbed, 12)
+	for i := range embeds {
+		embeds[i] = &discordgo.MessageEmbed{Title: fmt.Sprint(i), Description: strings.Repeat("x", 100)}
+	}
+
+	large := []*discordgo.MessageEmbed{
+		{Description: strings.Repeat("x", 4000)},
+		{Description: strings.Repeat("x", 4000)},
+	}
+
+	tests := []struct {
+		name       string
+		response   bot.Response
+		wantEmbeds []int
+		wantText   []bool
+	}{
+		{name: "text only", response: bot.Text("hello"), wantEmbeds: []int{0}, wantText: []bool{true}},
+		{name: "text with embeds", response: bot.Response{Content: "hello", Embeds: embeds[:2]}, wantEmbeds: []int{2}, wantText: []bool{true}},
+		{name: "at most ten embeds per message", response: bot.Response{Embeds: embeds}, wantEmbeds: []int{10, 2}, wantText: []bool{false, false}},
+		{name: "embeds within the total length", response: bot.Response{Embeds: large}, wantEmbeds: []int{1, 1}, wantText: []bool{false, false}},
+		{name: "long text", response: bot.Text(strings.Repeat("line\n", 500)), wantEmbeds: []int{0, 0}, wantText: []bool{true, true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.response.Messages()
+			if len(got) != len(tt.wantEmbeds) {
+				t.Fatalf("Response.Messages() = %d messages, want %d", len(got), len(tt.wantEmbeds))
+			}
+
+			for i, message := range got {
+				if len(message.Embeds) != tt.wantEmbeds[i] || (message.Content != "") != tt.wantText[i] {
+					t.Errorf("Response.Messages()[%d] = %d embeds, content %q", i, len(message.Embeds), message.Content)
+				}
+			}
+		})
+	}
+}
+
+func TestResponse_MessagesFiles(t *testing.T) {
+	files := make([]bot.Attachment, 12)
+	for i := range files {
+		files[i] = bot.Attachment{Name: fmt.Sprintf("ladder-%d.csv", i), ContentType: "text/csv", Data: []byte("Rank,Team\n")}
+	}
+
+	tests := []struct {
+		name      string
+		response  bot.Response
+		wantFiles []int
+	}{
+		{name: "file only", response: bot.Response{Files: files[:1]}, wantFiles: []int{1}},
+		{name: "attached to the last message", response: bot.Response{Content: strings.Repeat("line\n", 500), Files: files[:2]}, wantFiles: []int{0, 2}},
+		{name: "at most ten files per message", response: bot.Response{Content: "exports", Files: files}, wantFiles: []int{10, 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.response.Messages()
+			if len(got) != len(tt.wantFiles) {
+				t.Fatalf("Response.Messages() = %d messages, want %d", len(got), len(tt.wantFiles))
+			}
+
+			for i, message := range got {
+				if len(message.Files) != tt.wantFiles[i] {
+					t.Errorf("Response.Messages()[%d] = %d files, want %d", i, len(message.Files), tt.wantFiles[i])
+				}
+			}
+		})
+	}
+
+	// every call gets its own readers so a response can be sent more than once.
+	response := bot.Response{Files: files[:1]}
+	for i := 0; i < 2; i++ {
+		data, err := io.ReadAll(response.Messages()[0].Files[0].Reader)
+		if err != nil || string(data) != "Rank,Team\n" {
+			t.Errorf("Response.Messages() file data = %q, %v, want the attachment data", data, err)
+		}
+	}
+}
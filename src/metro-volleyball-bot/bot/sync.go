@@ -0,0 +1,155 @@
+package bot
+
+import (
+	"fmt"
+	"log/slog"
+	"reflect"
+	"sort"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// CommandSyncer lists and replaces the registered commands, *discordgo.Session implements
+// it.
+type CommandSyncer interface {
+	ApplicationCommands(appID, guildID string, options ...discordgo.RequestOption) ([]*discordgo.ApplicationCommand, error)
+	ApplicationCommandBulkOverwrite(appID string, guildID string, commands []*discordgo.ApplicationCommand, options ...discordgo.RequestOption) ([]*discordgo.ApplicationCommand, error)
+}
+
+// CommandDiff is how the registered commands differ from the desired commands, by name.
+type CommandDiff struct {
+	Create []string
+	Update []string
+	Delete []string
+}
+
+// Empty reports whether the registered commands are already the desired commands.
+func (d CommandDiff) Empty() bool {
+	return len(d.Create) == 0 && len(d.Update) == 0 && len(d.Delete) == 0
+}
+
+// DiffCommands compares the commands discord has registered with the desired commands.
+// Only the fields the bot sets are compared, ids and versions assigned by discord are
+// ignored.
+func DiffCommands(desired, registered []*discordgo.ApplicationCommand) CommandDiff {
+	existing := map[string]*discordgo.ApplicationCommand{}
+	for _, command := range registered {
+		existing[command.Name] = command
+	}
+
+	var diff CommandDiff
+	for _, command := range desired {
+		current, ok := existing[command.Name]
+		switch {
+		case !ok:
+			diff.Create = append(diff.Create, command.Name)
+		case !sameCommand(command, current):
+			diff.Update = append(diff.Update, command.Name)
+		}
+		delete(existing, command.Name)
+	}
+
+	for name := range existing {
+		diff.Delete = append(diff.Delete, name)
+	}
+	sort.Strings(diff.Delete)
+
+	return diff
+}
+
+// SyncCommands makes the commands registered for the guild the desired commands, an
+// empty guildID syncs the global commands. Discord is only asked to overwrite the
+// commands when they differ, so syncing on every start doesn't touch unchanged commands.
+func SyncCommands(s CommandSyncer, appID, guildID string, desired []*discordgo.ApplicationCommand) (CommandDiff, error) {
+	registered, err := s.ApplicationCommands(appID, guildID)
+	if err != nil {
+		return CommandDiff{}, fmt.Errorf("SyncCommands() unable to list commands, got: %w", err)
+	}
+
+	diff := DiffCommands(desired, registered)
+	if diff.Empty() {
+		return diff, nil
+	}
+
+	// a nil slice would be sent as null rather than an empty list.
+	if desired == nil {
+		desired = []*discordgo.ApplicationCommand{}
+	}
+
+	if _, err := s.ApplicationCommandBulkOverwrite(appID, guildID, desired); err != nil {
+		return CommandDiff{}, fmt.Errorf("SyncCommands() unable to overwrite commands, got: %w", err)
+	}
+
+	slog.Info("commands synced", "guild_id", guildID, "created", diff.Create, "updated", diff.Update, "deleted", diff.Delete)
+
+	return diff, nil
+}
+
+func sameCommand(a, b *discordgo.ApplicationCommand) bool {
+	return a.Name == b.Name &&
+		a.Description == b.Description &&
+		commandType(a.Type) == commandType(b.Type) &&
+		samePermissions(a.DefaultMemberPermissions, b.DefaultMemberPermissions) &&
+		sameOptions(a.Options, b.Options)
+}
+
+// commandType defaults to a slash command when it isn't set.
+func commandType(t discordgo.ApplicationCommandType) discordgo.ApplicationCommandType {
+	if t == 0 {
+		return discordgo.ChatApplicationCommand
+	}
+
+	return t
+}
+
+func samePermissions(a, b *int64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return *a == *b
+}
+
+func sameOptions(a, b []*discordgo.ApplicationCommandOption) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		x, y := a[i], b[i]
+		if x.Type != y.Type || x.Name != y.Name || x.Description != y.Description ||
+			x.Required != y.Required || x.Autocomplete != y.Autocomplete ||
+			x.MaxValue != y.MaxValue || x.MaxLength != y.MaxLength {
+			return false
+		}
+
+		if !reflect.DeepEqual(x.MinValue, y.MinValue) || !reflect.DeepEqual(x.MinLength, y.MinLength) {
+			return false
+		}
+
+		if len(x.ChannelTypes) != len(y.ChannelTypes) || (len(x.ChannelTypes) > 0 && !reflect.DeepEqual(x.ChannelTypes, y.ChannelTypes)) {
+			return false
+		}
+
+		if !sameChoices(x.Choices, y.Choices) || !sameOptions(x.Options, y.Options) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// sameChoices compares the choices' values as text, discord returns numbers as floats.
+func sameChoices(a, b []*discordgo.ApplicationCommandOptionChoice) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i].Name != b[i].Name || fmt.Sprint(a[i].Value) != fmt.Sprint(b[i].Value) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,147 @@
+package bot_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/brewinski/home-lab-server/src/metro-volleyball-bot/bot"
+	"github.com/bwmarrin/discordgo"
+)
+
+// fakeSyncer keeps the registered commands for each guild.
+type fakeSyncer struct {
+	registered map[string][]*discordgo.ApplicationCommand
+	overwrites int
+	listErr    error
+}
+
+func (f *fakeSyncer) ApplicationCommands(appID, guildID string, options ...discordgo.RequestOption) ([]*discordgo.ApplicationCommand, error) {
+	return f.registered[guildID], f.listErr
+}
+
+func (f *fakeSyncer) ApplicationCommandBulkOverwrite(appID string, guildID string, commands []*discordgo.ApplicationCommand, options ...discordgo.RequestOption) ([]*discordgo.ApplicationCommand, error) {
+	f.overwrites++
+	f.registered[guildID] = commands
+	return commands, nil
+}
+
+var manageServer int64 = discordgo.PermissionManageServer
+
+// registered is how discord returns a command, with its ids and defaults filled in.
+func registered(command *discordgo.ApplicationCommand) *discordgo.ApplicationCommand {
+	copied := *command
+	copied.ID = "id-" + command.Name
+	copied.ApplicationID = "app"
+	copied.Version = "1234"
+	copied.Type = discordgo.ChatApplicationCommand
+	return &copied
+}
+
+func TestDiffCommands(t *testing.T) {
+	ladder := &discordgo.ApplicationCommand{Name: "vb-ladder", Description: "view the ladder.", Options: []*discordgo.ApplicationCommandOption{
+		{Type: discordgo.ApplicationCommandOptionString, Name: "view", Description: "the view.", Choices: []*discordgo.ApplicationCommandOptionChoice{{Name: "pools", Value: "pools"}}},
+	}}
+	subscribe := &discordgo.ApplicationCommand{Name: "vb-subscribe", Description: "subscribe.", DefaultMemberPermissions: &manageServer}
+
+	renamed := registered(ladder)
+	renamed.Description = "view the latest ladder."
+
+	optional := registered(ladder)
+	optional.Options = []*discordgo.ApplicationCommandOption{{Type: discordgo.ApplicationCommandOptionString, Name: "view", Description: "the view."}}
+
+	open := registered(subscribe)
+	open.DefaultMemberPermissions = nil
+
+	tests := []struct {
+		name       string
+		registered []*discordgo.ApplicationCommand
+		want       bot.CommandDiff
+	}{
+		{name: "unchanged", registered: []*discordgo.ApplicationCommand{registered(ladder), registered(subscribe)}, want: bot.CommandDiff{}},
+		{name: "nothing registered", want: bot.CommandDiff{Create: []string{"vb-ladder", "vb-subscribe"}}},
+		{name: "description changed", registered: []*discordgo.ApplicationCommand{renamed, registered(subscribe)}, want: bot.CommandDiff{Update: []string{"vb-ladder"}}},
+		{name: "choices changed", registered: []*discordgo.ApplicationCommand{optional, registered(subscribe)}, want: bot.CommandDiff{Update: []string{"vb-ladder"}}},
+		{name: "permissions changed", registered: []*discordgo.ApplicationCommand{registered(ladder), open}, want: bot.CommandDiff{Update: []string{"vb-subscribe"}}},
+		{
+			name:       "command removed",
+			registered: []*discordgo.ApplicationCommand{registered(ladder), registered(subscribe), {Name: "vb-old", Description: "old."}},
+			want:       bot.CommandDiff{Delete: []string{"vb-old"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := bot.DiffCommands([]*discordgo.ApplicationCommand{ladder, subscribe}, tt.registered)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DiffCommands() = %+v, want %+v", got, tt.want)
+			}
+
+			if got.Empty() != reflect.DeepEqual(tt.want, bot.CommandDiff{}) {
+				t.Errorf("CommandDiff.Empty() = %v for %+v", got.Empty(), got)
+			}
+		})
+	}
+}
+
+func TestSyncCommands(t *testing.T) {
+	desired := bot.NewRegistry().ApplicationCommands()
+	s := &fakeSyncer{registered: map[string][]*discordgo.ApplicationCommand{}}
+
+	diff, err := bot.SyncCommands(s, "app", "g1", desired)
+	if err != nil || !reflect.DeepEqual(diff.Create, []string{bot.HelpCommand}) || s.overwrites != 1 {
+		t.Fatalf("SyncCommands() = %+v, %v after %d overwrites, want the commands created", diff, err, s.overwrites)
+	}
+
+	// discord returns the commands with ids, a second sync leaves them alone.
+	for i, command := range s.registered["g1"] {
+		s.registered["g1"][i] = registered(command)
+	}
+
+	diff, err = bot.SyncCommands(s, "app", "g1", desired)
+	if err != nil || !diff.Empty() || s.overwrites != 1 {
+		t.Errorf("SyncCommands() = %+v, %v after %d overwrites, want nothing to sync", diff, err, s.overwrites)
+	}
+
+	// no commands clears the guild, e.g. when switching to global commands.
+	diff, err = bot.SyncCommands(s, "app", "g1", nil)
+	if err != nil || !reflect.DeepEqual(diff.Delete, []string{bot.HelpCommand}) || s.registered["g1"] == nil || len(s.registered["g1"]) != 0 {
+		t.Errorf("SyncCommands() = %+v, %v with %v registered, want an empty list sent", diff, err, s.registered["g1"])
+	}
+
+	s.listErr = errors.New("missing access")
+	if _, err := bot.SyncCommands(s, "app", "g1", desired); !errors.Is(err, s.listErr) {
+		t.Errorf("SyncCommands() error = %v, want %v", err, s.listErr)
+	}
+}
+
+func TestBot_SyncGlobalCommands(t *testing.T) {
+	tests := []struct {
+		name           string
+		globalCommands bool
+		want           []string
+	}{
+		{name: "global commands registers them globally", globalCommands: true, want: []string{bot.HelpCommand}},
+		{name: "guild commands clears stale global commands", globalCommands: false, want: []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// a previous run left a global command registered.
+			s := &fakeSyncer{registered: map[string][]*discordgo.ApplicationCommand{
+				"": {registered(&discordgo.ApplicationCommand{Name: "vb-old", Description: "old."})},
+			}}
+			b := bot.New(bot.Config{Commands: bot.NewRegistry(), GlobalCommands: tt.globalCommands})
+
+			if _, err := b.SyncGlobalCommands(s, "app"); err != nil {
+				t.Fatalf("Bot.SyncGlobalCommands() error = %v", err)
+			}
+
+			got := []string{}
+			for _, command := range s.registered[""] {
+				got = append(got, command.Name)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Bot.SyncGlobalCommands() registered %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,9 +1,242 @@
 package cfg
 
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
 const (
-	VQBaseUrl = "https://vqmetro24s1.softr.app/v1/integrations/airtable/dc83c433-262d-48a0-915f-2cf124cceeb8/app4eDFcW0KK8A7xt"
-	// VQ Ladder specific variables (change between seasons)
-	VQLadderPath            = "/Ladder/records?block_id=4cf2b9cc-8241-4332-9df5-47a68e375c5a"
-	VQLadderPageID          = "a7233511-bb1c-4840-9f02-d3198caf05f4"
-	VQLadderFilterByFormula = "(LOWER(\"MD\") = LOWER(ARRAYJOIN({Division})))"
+	// ConfigEnv is the environment variable used to find the config file when the -config
+	// flag isn't provided.
+	ConfigEnv = "VQ_CONFIG"
 )
+
+// DefaultCompetition is used when no config file is provided, and fills in any endpoint
+// settings that are missing from a client config.
+var DefaultCompetition = Competition{
+	Name:        "vqmetro24s1",
+	BaseURL:     "https://vqmetro24s1.softr.app/v1/integrations/airtable/dc83c433-262d-48a0-915f-2cf124cceeb8/app4eDFcW0KK8A7xt",
+	Division:    "MD",
+	DefaultTeam: "aces",
+	LadderURL:   "https://vqmetro24s1.softr.app/ladder-m1",
+	Ladder: Endpoint{
+		Table:   "Ladder",
+		BlockID: "4cf2b9cc-8241-4332-9df5-47a68e375c5a",
+		PageID:  "a7233511-bb1c-4840-9f02-d3198caf05f4",
+	},
+	// the 2024 season 1 games block isn't known, a config file has to provide it. Until
+	// then the bot runs without the games commands, fixture watchers and reminders rather
+	// than querying a dead season.
+	Games: Endpoint{
+		Table: "Competition Manager",
+	},
+}
+
+// Config describes the competitions the bot can follow. A new season only needs a new
+// config file, not a new build.
+type Config struct {
+	Competitions []Competition `yaml:"competitions" json:"competitions"`
+}
+
+// Competition is a single VQ metro season hosted on softr.
+type Competition struct {
+	// Name identifies the competition, e.g. "vqmetro24s1".
+	Name string `yaml:"name" json:"name"`
+	// BaseURL is the softr airtable integration url the endpoint paths are appended to.
+	BaseURL string `yaml:"base_url" json:"base_url"`
+	// Division is the division code used to filter the ladder, e.g. "MD".
+	Division string `yaml:"division" json:"division"`
+	// DefaultTeam is used by team commands when the user doesn't provide a team.
+	DefaultTeam string `yaml:"default_team" json:"default_team"`
+	// LadderURL is the public ladder page linked from ladder messages. Optional.
+	LadderURL string   `yaml:"ladder_url" json:"ladder_url"`
+	Ladder    Endpoint `yaml:"ladder" json:"ladder"`
+	// Games is optional, the features that need the fixtures are off without its ids.
+	Games Endpoint `yaml:"games" json:"games"`
+}
+
+// HasGames reports whether the games endpoint's ids are configured.
+func (c Competition) HasGames() bool {
+	return c.Games.BlockID != "" && c.Games.PageID != ""
+}
+
+// Endpoint is an airtable table exposed through a softr block.
+type Endpoint struct {
+	Table   string `yaml:"table" json:"table"`
+	BlockID string `yaml:"block_id" json:"block_id"`
+	PageID  string `yaml:"page_id" json:"page_id"`
+}
+
+// Path returns the records path for the endpoint, relative to the competition base url.
+func (e Endpoint) Path() string {
+	return "/" + url.PathEscape(e.Table) + "/records?block_id=" + url.QueryEscape(e.BlockID)
+}
+
+// Default returns the config used when no config file is provided.
+func Default() Config {
+	return Config{
+		Competitions: []Competition{DefaultCompetition},
+	}
+}
+
+// Load reads and validates the config file at path. Files ending in .json are decoded as
+// json, everything else as yaml. Unknown keys are rejected so typos don't go unnoticed.
+func Load(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, fmt.Errorf("Load() unable to read config file, got: %w", err)
+	}
+
+	var config Config
+
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(&config)
+	} else {
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		err = decoder.Decode(&config)
+	}
+
+	if err != nil {
+		return Config{}, fmt.Errorf("Load() unable to decode config file %s, got: %w", path, err)
+	}
+
+	if err := config.Validate(); err != nil {
+		return Config{}, fmt.Errorf("Load() invalid config file %s: %w", path, err)
+	}
+
+	return config, nil
+}
+
+// Validate checks every competition has the settings needed to build a client. All the
+// problems found are returned together.
+func (c Config) Validate() error {
+	if len(c.Competitions) == 0 {
+		return errors.New("at least one competition is required")
+	}
+
+	var errs []error
+	names := map[string]bool{}
+
+	for i, competition := range c.Competitions {
+		if competition.Name == "" {
+			errs = append(errs, fmt.Errorf("competitions[%d]: name is required", i))
+		} else if names[competition.Name] {
+			errs = append(errs, fmt.Errorf("competitions[%d]: duplicate name %q", i, competition.Name))
+		}
+		names[competition.Name] = true
+
+		if err := competition.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("competitions[%d]: %w", i, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Validate checks the competition has the settings needed to build a client.
+func (c Competition) Validate() error {
+	var errs []error
+
+	if u, err := url.Parse(c.BaseURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		errs = append(errs, fmt.Errorf("base_url %q must be an absolute http(s) url", c.BaseURL))
+	}
+
+	if c.LadderURL != "" {
+		if u, err := url.Parse(c.LadderURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = append(errs, fmt.Errorf("ladder_url %q must be an absolute http(s) url", c.LadderURL))
+		}
+	}
+
+	if c.Division == "" {
+		errs = append(errs, errors.New("division is required"))
+	}
+
+	if err := c.Ladder.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("ladder: %w", err))
+	}
+
+	// games are optional, but half configured ids are a mistake.
+	if c.Games.BlockID != "" || c.Games.PageID != "" {
+		if err := c.Games.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("games: %w", err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Validate checks the endpoint has a table, block id and page id.
+func (e Endpoint) Validate() error {
+	var errs []error
+
+	if e.Table == "" {
+		errs = append(errs, errors.New("table is required"))
+	}
+
+	if e.BlockID == "" {
+		errs = append(errs, errors.New("block_id is required"))
+	}
+
+	if e.PageID == "" {
+		errs = append(errs, errors.New("page_id is required"))
+	}
+
+	return errors.Join(errs...)
+}
+
+// HasGames reports whether any competition has its games endpoint configured.
+func (c Config) HasGames() bool {
+	for _, competition := range c.Competitions {
+		if competition.HasGames() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Competition returns the competition with the given name. An empty name returns the
+// first competition in the config.
+func (c Config) Competition(name string) (Competition, error) {
+	if name == "" && len(c.Competitions) > 0 {
+		return c.Competitions[0], nil
+	}
+
+	for _, competition := range c.Competitions {
+		if competition.Name == name {
+			return competition, nil
+		}
+	}
+
+	return Competition{}, fmt.Errorf("Competition() no competition named %q", name)
+}
+
+// WithCompetition returns a copy of the config with the competition of the same name
+// replaced, or added when there isn't one.
+func (c Config) WithCompetition(competition Competition) Config {
+	competitions := make([]Competition, 0, len(c.Competitions)+1)
+	replaced := false
+	for _, existing := range c.Competitions {
+		if existing.Name == competition.Name {
+			existing, replaced = competition, true
+		}
+		competitions = append(competitions, existing)
+	}
+
+	if !replaced {
+		competitions = append(competitions, competition)
+	}
+
+	return Config{Competitions: competitions}
+}
@@ -0,0 +1,224 @@
+package cfg_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/brewinski/home-lab-server/src/metro-volleyball-bot/cfg"
+)
+
+const validYaml = `
+competitions:
+  - name: vqmetro24s1
+    base_url: https://vqmetro24s1.softr.app/v1/integrations/airtable/app
+    division: MD
+    default_team: aces
+    ladder:
+      table: Ladder
+      block_id: ladder-block
+      page_id: ladder-page
+    games:
+      table: Competition Manager
+      block_id: games-block
+      page_id: games-page
+`
+
+const validJson = `{
+  "competitions": [
+    {
+      "name": "vqmetro24s1",
+      "base_url": "https://vqmetro24s1.softr.app/v1/integrations/airtable/app",
+      "division": "MD",
+      "default_team": "aces",
+      "ladder": {"table": "Ladder", "block_id": "ladder-block", "page_id": "ladder-page"},
+      "games": {"table": "Competition Manager", "block_id": "games-block", "page_id": "games-page"}
+    }
+  ]
+}`
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		contents string
+		// substrings expected in the error, no error is expected when empty.
+		wantErrs []string
+	}{
+		{
+			name:     "load a yaml config",
+			file:     "config.yaml",
+			contents: validYaml,
+		},
+		{
+			name:     "load a json config",
+			file:     "config.json",
+			contents: validJson,
+		},
+		{
+			name:     "reject unknown keys",
+			file:     "config.yaml",
+			contents: validYaml + "unknown: true\n",
+			wantErrs: []string{"unknown"},
+		},
+		{
+			name:     "reject a config without competitions",
+			file:     "config.yaml",
+			contents: "competitions: []\n",
+			wantErrs: []string{"at least one competition"},
+		},
+		{
+			name: "report every invalid setting",
+			file: "config.yaml",
+			contents: `
+competitions:
+  - name: broken
+    base_url: not-a-url
+    ladder:
+      table: Ladder
+  - name: broken
+    base_url: https://example.com
+    ladder_url: /ladder
+    division: MD
+    ladder:
+      table: Ladder
+      block_id: block
+      page_id: page
+    games:
+      table: Competition Manager
+      block_id: block
+`,
+			wantErrs: []string{"base_url", "division is required", "ladder: block_id is required", "page_id is required", "games: page_id is required", "duplicate name", "ladder_url"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			if err := os.WriteFile(path, []byte(tt.contents), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := cfg.Load(path)
+			if (err != nil) != (len(tt.wantErrs) > 0) {
+				t.Errorf("Load() error = %v, wantErrs %v", err, tt.wantErrs)
+				return
+			}
+
+			for _, want := range tt.wantErrs {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("Load() error = %v, want it to mention %q", err, want)
+				}
+			}
+
+			if err != nil {
+				return
+			}
+
+			competition, err := got.Competition("")
+			if err != nil {
+				t.Fatalf("Config.Competition() error = %v", err)
+			}
+
+			if competition.Name != "vqmetro24s1" || competition.DefaultTeam != "aces" {
+				t.Errorf("Load() competition = %+v", competition)
+			}
+
+			if path := competition.Ladder.Path(); path != "/Ladder/records?block_id=ladder-block" {
+				t.Errorf("Endpoint.Path() = %v", path)
+			}
+
+			if path := competition.Games.Path(); path != "/Competition%20Manager/records?block_id=games-block" {
+				t.Errorf("Endpoint.Path() = %v", path)
+			}
+		})
+	}
+}
+
+func TestDefault(t *testing.T) {
+	if err := cfg.Default().Validate(); err != nil {
+		t.Errorf("Default() is invalid: %v", err)
+	}
+}
+
+func TestCompetition_HasGames(t *testing.T) {
+	tests := []struct {
+		name    string
+		games   cfg.Endpoint
+		want    bool
+		wantErr bool
+	}{
+		{name: "configured", games: cfg.Endpoint{Table: "Competition Manager", BlockID: "games-block", PageID: "games-page"}, want: true},
+		{name: "not configured", games: cfg.Endpoint{Table: "Competition Manager"}, want: false},
+		{name: "half configured", games: cfg.Endpoint{Table: "Competition Manager", BlockID: "games-block"}, want: false, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			competition := cfg.DefaultCompetition
+			competition.Games = tt.games
+
+			if got := competition.HasGames(); got != tt.want {
+				t.Errorf("Competition.HasGames() = %v, want %v", got, tt.want)
+			}
+
+			if err := competition.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Competition.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfig_Competition(t *testing.T) {
+	config := cfg.Config{
+		Competitions: []cfg.Competition{{Name: "first"}, {Name: "second"}},
+	}
+
+	tests := []struct {
+		name    string
+		lookup  string
+		want    string
+		wantErr bool
+	}{
+		{name: "empty name returns the first competition", lookup: "", want: "first"},
+		{name: "return the competition by name", lookup: "second", want: "second"},
+		{name: "unknown names are an error", lookup: "third", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := config.Competition(tt.lookup)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Config.Competition() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got.Name != tt.want {
+				t.Errorf("Config.Competition() = %v, want %v", got.Name, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfig_WithCompetition(t *testing.T) {
+	config := cfg.Config{
+		Competitions: []cfg.Competition{{Name: "first"}, {Name: "second"}},
+	}
+
+	replaced := config.WithCompetition(cfg.Competition{Name: "second", BaseURL: "http://localhost"})
+	if got, _ := replaced.Competition("second"); got.BaseURL != "http://localhost" || len(replaced.Competitions) != 2 {
+		t.Errorf("Config.WithCompetition() = %+v, want second replaced", replaced)
+	}
+
+	if got, _ := config.Competition("second"); got.BaseURL != "" {
+		t.Errorf("Config.WithCompetition() changed the original config")
+	}
+
+	added := config.WithCompetition(cfg.Competition{Name: "third"})
+	if len(added.Competitions) != 3 || added.Competitions[2].Name != "third" {
+		t.Errorf("Config.WithCompetition() = %+v, want third added", added)
+	}
+}
+
+func TestLoad_ExampleConfig(t *testing.T) {
+	if _, err := cfg.Load("../config.example.yaml"); err != nil {
+		t.Errorf("Load() example config is invalid: %v", err)
+	}
+}
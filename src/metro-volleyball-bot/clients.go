@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/brewinski/home-lab-server/src/metro-volleyball-bot/cfg"
+	"github.com/brewinski/home-lab-server/src/metro-volleyball-bot/vq"
+)
+
+// clientPool hands out a vq client for each competition and division, creating them the
+// first time they're needed so commands and watchers share their caches.
+type clientPool struct {
+	config cfg.Config
+	build  func(competition cfg.Competition, division string) *vq.Client
+
+	mu      sync.Mutex
+	clients map[string]*vq.Client
+}
+
+func newClientPool(config cfg.Config, build func(competition cfg.Competition, division string) *vq.Client) *clientPool {
+	return &clientPool{config: config, build: build, clients: map[string]*vq.Client{}}
+}
+
+// Client returns the client for the competition's division. An empty division uses the
+// competition's configured division.
+func (p *clientPool) Client(competitionName, division string) (*vq.Client, error) {
+	competition, err := p.config.Competition(competitionName)
+	if err != nil {
+		return nil, fmt.Errorf("clientPool.Client() unknown competition: %w", err)
+	}
+
+	if division == "" {
+		division = competition.Division
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	key := competition.Name + "/" + strings.ToUpper(division)
+	client, ok := p.clients[key]
+	if !ok {
+		client = p.build(competition, division)
+		p.clients[key] = client
+	}
+
+	return client, nil
+}
+
+// Competitions returns the names of the competitions in the config.
+func (p *clientPool) Competitions() []string {
+	names := make([]string, 0, len(p.config.Competitions))
+	for _, competition := range p.config.Competitions {
+		names = append(names, competition.Name)
+	}
+
+	return names
+}
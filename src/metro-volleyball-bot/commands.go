@@ -0,0 +1,359 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/brewinski/home-lab-server/src/metro-volleyball-bot/bot"
+	"github.com/brewinski/home-lab-server/src/metro-volleyball-bot/history"
+	"github.com/brewinski/home-lab-server/src/metro-volleyball-bot/subscription"
+	"github.com/brewinski/home-lab-server/src/metro-volleyball-bot/vq"
+	"github.com/bwmarrin/discordgo"
+)
+
+// only members who can manage the server may change its subscription.
+var manageServer int64 = discordgo.PermissionManageServer
+
+// commandEnv is what the command handlers share.
+type commandEnv struct {
+	// vqClient is for the division the bot follows.
+	vqClient      *vq.Client
+	clients       *clientPool
+	subscriptions *subscription.Registry
+	// defaults is the competition, division and team the bot follows.
+	defaults subscription.Subscription
+	// subscription returns what a guild follows, commands fall back to it when an option
+	// is left out. Optional, every guild follows the defaults without it.
+	subscription  func(guildID string) (subscription.Subscription, error)
+	archive       *history.Archive
+	ladderOptions bot.LadderEmbedOptions
+	// games is false when no competition has a games endpoint, the commands that need
+	// the fixtures are left out.
+	games bool
+}
+
+// gamesCommands need the fixtures. History is labelled with the rounds played, so it's
+// empty without them.
+var gamesCommands = map[string]bool{"vb-next-game": true, "vb-h2h": true, "vb-odds": true, "vb-history": true}
+
+// noGames is the reply to commands that need the fixtures of a competition without a
+// games endpoint.
+const noGames = "fixtures aren't available, this competition's games aren't configured"
+
+// noTeam is the reply to team commands run without a team in a guild that doesn't follow
+// one.
+const noTeam = "choose a team, this server doesn't follow one"
+
+// following returns what the guild follows. Guilds that unsubscribed, or whose
+// subscription can't be read, get the defaults.
+func (env commandEnv) following(guildID string) subscription.Subscription {
+	if env.subscription == nil {
+		return env.defaults
+	}
+
+	sub, err := env.subscription(guildID)
+	if err != nil {
+		slog.Error("get guild subscription", "error", err, "guild_id", guildID)
+		return env.defaults
+	}
+
+	if sub.Disabled {
+		return env.defaults
+	}
+
+	return sub
+}
+
+// client returns the client for the subscription's division, the bot's own client when
+// it's the division the bot follows.
+func (env commandEnv) client(sub subscription.Subscription) (*vq.Client, error) {
+	if env.clients == nil || (strings.EqualFold(sub.Competition, env.defaults.Competition) && strings.EqualFold(sub.Division, env.defaults.Division)) {
+		return env.vqClient, nil
+	}
+
+	return env.clients.Client(sub.Competition, sub.Division)
+}
+
+// commands are the bot's slash commands, registered alongside the generated help command.
+func commands(env commandEnv) []bot.Command {
+	var available []bot.Command
+	for _, command := range allCommands(env) {
+		if env.games || !gamesCommands[command.Name] {
+			available = append(available, command)
+		}
+	}
+
+	return available
+}
+
+func allCommands(env commandEnv) []bot.Command {
+	return []bot.Command{
+		{
+			Name:        "vb-ladder",
+			Description: "view the latest ladder results.",
+			Help:        "shows the standings with the followed team highlighted.",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "view",
+					Description: "rank teams overall, within their pools, or across the pools.",
+					Required:    false,
+					Choices:     viewChoices(),
+				},
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "division",
+					Description:  "the division to show, defaults to the division this server follows.",
+					Required:     false,
+					Autocomplete: true,
+				},
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "team",
+					Description:  "the team to highlight, defaults to the team this server follows.",
+					Required:     false,
+					Autocomplete: true,
+				},
+			},
+			Handler: func(ctx context.Context, req bot.Request) (bot.Response, error) {
+				sub := env.following(req.GuildID)
+				division := req.Options.String("division", sub.Division)
+				opts := env.ladderOptions
+				opts.Title = "Ladder " + division
+				opts.View = req.Options.String("view", vq.ViewOverall)
+				opts.Highlight = req.Options.String("team", sub.Team)
+				slog.Info("vb-ladder command received", "competition", sub.Competition, "division", division, "view", opts.View, "team", opts.Highlight)
+
+				vqClient, err := env.client(sub)
+				if err != nil {
+					return bot.Response{}, err
+				}
+
+				// the link is to the followed competition's ladder page.
+				if env.clients != nil && !strings.EqualFold(sub.Competition, env.defaults.Competition) {
+					competition, _ := env.clients.config.Competition(sub.Competition)
+					opts.URL = competition.LadderURL
+				}
+
+				return ladderReply(ctx, vqClient, division, opts)
+			},
+		},
+		{
+			Name:        "vb-next-game",
+			Description: "view the next game for the team.",
+			Help:        "shows the time, venue, court and opponent of the team's next game.",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "team",
+					Description:  "the team to look up, defaults to the team this server follows.",
+					Required:     false,
+					Autocomplete: true,
+				},
+			},
+			Handler: func(ctx context.Context, req bot.Request) (bot.Response, error) {
+				sub := env.following(req.GuildID)
+				team := req.Options.String("team", sub.Team)
+				slog.Info("vb-next-game command received", "team", team)
+
+				if team == "" {
+					return bot.Text(noTeam), nil
+				}
+
+				vqClient, err := env.client(sub)
+				if err != nil {
+					return bot.Response{}, err
+				}
+
+				return text(nextGameReply(ctx, vqClient, team, time.Now()))
+			},
+		},
+		{
+			Name:        "vb-h2h",
+			Description: "view the record between two teams and their next meeting.",
+			Help:        "shows the results of every game the two teams have played this season.",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "team_b",
+					Description:  "the team to compare against.",
+					Required:     true,
+					Autocomplete: true,
+				},
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "team_a",
+					Description:  "the first team, defaults to the team this server follows.",
+					Required:     false,
+					Autocomplete: true,
+				},
+			},
+			Handler: func(ctx context.Context, req bot.Request) (bot.Response, error) {
+				sub := env.following(req.GuildID)
+				teamA := req.Options.String("team_a", sub.Team)
+				teamB := req.Options.String("team_b", "")
+				slog.Info("vb-h2h command received", "team_a", teamA, "team_b", teamB)
+
+				if teamA == "" {
+					return bot.Text(noTeam), nil
+				}
+
+				vqClient, err := env.client(sub)
+				if err != nil {
+					return bot.Response{}, err
+				}
+
+				return text(headToHeadReply(ctx, vqClient, teamA, teamB, time.Now()))
+			},
+		},
+		{
+			Name:        "vb-odds",
+			Description: "view each team's chance of making finals.",
+			Help:        "simulates the rest of the season from the current ladder and remaining fixtures.",
+			Handler: func(ctx context.Context, req bot.Request) (bot.Response, error) {
+				slog.Info("vb-odds command received")
+
+				return text(oddsReply(ctx, env.vqClient, time.Now()))
+			},
+		},
+		{
+			Name:        "vb-history",
+			Description: "view the team's ladder position round by round.",
+			Help:        "built from the ladders the bot has seen, so it starts when the bot started following.",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "team",
+					Description:  "the team to look up, defaults to the team this server follows.",
+					Required:     false,
+					Autocomplete: true,
+				},
+			},
+			Handler: func(ctx context.Context, req bot.Request) (bot.Response, error) {
+				team := req.Options.String("team", env.following(req.GuildID).Team)
+				slog.Info("vb-history command received", "team", team)
+
+				if team == "" {
+					return bot.Text(noTeam), nil
+				}
+
+				return text(historyReply(env.archive, team))
+			},
+		},
+		{
+			Name:        "vb-fairness",
+			Description: "compare the early slots, late slots and duties each team has been given.",
+			Help:        "flags teams more than a standard deviation from the mean.",
+			Handler: func(ctx context.Context, req bot.Request) (bot.Response, error) {
+				slog.Info("vb-fairness command received")
+
+				return text(fairnessReply(ctx, env.vqClient))
+			},
+		},
+		{
+			Name:                     "vb-subscribe",
+			Description:              "choose what this server follows and where updates are posted.",
+			Help:                     "ladder updates are posted for the division, and fixture changes and reminders for the team. needs the manage server permission.",
+			DefaultMemberPermissions: &manageServer,
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:         discordgo.ApplicationCommandOptionChannel,
+					Name:         "channel",
+					Description:  "the channel updates are posted to, defaults to this channel.",
+					Required:     false,
+					ChannelTypes: []discordgo.ChannelType{discordgo.ChannelTypeGuildText},
+				},
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "competition",
+					Description:  "the competition to follow, defaults to the competition the bot follows.",
+					Required:     false,
+					Autocomplete: true,
+				},
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "division",
+					Description:  "the division to follow, defaults to the division the bot follows.",
+					Required:     false,
+					Autocomplete: true,
+				},
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "team",
+					Description:  "the team to follow fixture changes for. optional.",
+					Required:     false,
+					Autocomplete: true,
+				},
+			},
+			Handler: func(ctx context.Context, req bot.Request) (bot.Response, error) {
+				slog.Info("vb-subscribe command received", "guild_id", req.GuildID)
+
+				return text(subscribeReply(ctx, env.clients, env.subscriptions, env.defaults, req))
+			},
+		},
+		{
+			Name:                     "vb-unsubscribe",
+			Description:              "stop posting updates to this server.",
+			Help:                     "needs the manage server permission.",
+			DefaultMemberPermissions: &manageServer,
+			Handler: func(ctx context.Context, req bot.Request) (bot.Response, error) {
+				slog.Info("vb-unsubscribe command received", "guild_id", req.GuildID)
+
+				return text(unsubscribeReply(env.subscriptions, req.GuildID))
+			},
+		},
+		{
+			Name:        "vb-export",
+			Description: "download the ladder or fixtures as a file.",
+			Help:        "the file is attached to the reply.",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "format",
+					Description: "the file format.",
+					Required:    true,
+					Choices:     formatChoices(),
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "data",
+					Description: "what to export, defaults to the ladder.",
+					Required:    false,
+					Choices: []*discordgo.ApplicationCommandOptionChoice{
+						{Name: "ladder", Value: "ladder"},
+						{Name: "fixtures", Value: "fixtures"},
+					},
+				},
+			},
+			Handler: func(ctx context.Context, req bot.Request) (bot.Response, error) {
+				format := req.Options.String("format", vq.FormatCSV)
+				data := req.Options.String("data", "ladder")
+				slog.Info("vb-export command received", "format", format, "data", data)
+
+				return exportReply(ctx, env.vqClient, format, data)
+			},
+		},
+	}
+}
+
+// formatChoices offers every registered export format.
+func formatChoices() []*discordgo.ApplicationCommandOptionChoice {
+	var choices []*discordgo.ApplicationCommandOptionChoice
+	for _, format := range vq.Formats() {
+		choices = append(choices, &discordgo.ApplicationCommandOptionChoice{Name: format, Value: format})
+	}
+
+	return choices
+}
+
+// viewChoices offers every ladder view.
+func viewChoices() []*discordgo.ApplicationCommandOptionChoice {
+	var choices []*discordgo.ApplicationCommandOptionChoice
+	for _, view := range vq.Views {
+		choices = append(choices, &discordgo.ApplicationCommandOptionChoice{Name: view, Value: view})
+	}
+
+	return choices
+}
@@ -2,7 +2,12 @@ module github.com/brewinski/home-lab-server/src/metro-volleyball-bot
 
 go 1.21
 
-require github.com/bwmarrin/discordgo v0.27.1
+require (
+	github.com/bwmarrin/discordgo v0.27.1
+	go.etcd.io/bbolt v1.3.10
+	golang.org/x/sync v0.6.0
+	gopkg.in/yaml.v3 v3.0.1
+)
 
 require (
 	github.com/gorilla/websocket v1.4.2 // indirect
@@ -0,0 +1,241 @@
+// Package history archives ladder snapshots so a team's progress can be shown round by
+// round.
+package history
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/brewinski/home-lab-server/src/metro-volleyball-bot/store"
+	"github.com/brewinski/home-lab-server/src/metro-volleyball-bot/vq"
+)
+
+// Snapshot is a distinct ladder seen at a point in time.
+type Snapshot struct {
+	Time time.Time `json:"time"`
+	// Round is the latest round with a result when the snapshot was taken, taken from the
+	// games rather than the ladder so byes and washed out games don't skew it. Empty in
+	// snapshots archived before it was recorded.
+	Round   string  `json:"completed_round,omitempty"`
+	Entries []Entry `json:"entries"`
+}
+
+// Entry is a team's position in a snapshot. Only what the history needs is kept, the
+// archive grows with every ladder change.
+type Entry struct {
+	Team   string    `json:"team"`
+	Rank   int       `json:"rank"`
+	Points float64   `json:"points"`
+	Record vq.Record `json:"record"`
+}
+
+// Archive is the list of snapshots kept in a store under a single key. It's safe for
+// concurrent use.
+type Archive struct {
+	store store.Store
+	key   string
+	mu    sync.Mutex
+}
+
+// NewArchive returns an archive kept in s under key.
+func NewArchive(s store.Store, key string) *Archive {
+	return &Archive{store: s, key: key}
+}
+
+// NewSnapshot builds a snapshot of the standings after the round.
+func NewSnapshot(standings []vq.Standing, round string, at time.Time) Snapshot {
+	snapshot := Snapshot{Time: at, Round: round, Entries: make([]Entry, 0, len(standings))}
+
+	for _, standing := range standings {
+		snapshot.Entries = append(snapshot.Entries, Entry{
+			Team:   standing.Team,
+			Rank:   standing.Rank,
+			Points: standing.CompetitionPoints,
+			Record: standing.Matches,
+		})
+	}
+
+	return snapshot
+}
+
+// Record saves the standings after the round as a new snapshot unless they're the same as
+// the last one. It returns true when a snapshot was saved.
+func (a *Archive) Record(standings []vq.Standing, round string, at time.Time) (bool, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	snapshots, err := a.load()
+	if err != nil {
+		return false, err
+	}
+
+	snapshot := NewSnapshot(standings, round, at)
+	if len(snapshots) > 0 && reflect.DeepEqual(snapshots[len(snapshots)-1].Entries, snapshot.Entries) {
+		return false, nil
+	}
+
+	snapshots = append(snapshots, snapshot)
+	if err := store.PutJSON(a.store, a.key, snapshots); err != nil {
+		return false, fmt.Errorf("Archive.Record() unable to save snapshot, got: %w", err)
+	}
+
+	return true, nil
+}
+
+// Snapshots returns every snapshot in the order they were recorded.
+func (a *Archive) Snapshots() ([]Snapshot, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.load()
+}
+
+func (a *Archive) load() ([]Snapshot, error) {
+	var snapshots []Snapshot
+	if _, err := store.GetJSON(a.store, a.key, &snapshots); err != nil {
+		return nil, fmt.Errorf("Archive.Snapshots() unable to load snapshots, got: %w", err)
+	}
+
+	return snapshots, nil
+}
+
+// TeamRound is a team's position at the end of a round.
+type TeamRound struct {
+	Round  string
+	Rank   int
+	Points float64
+	Record vq.Record
+}
+
+// TeamHistory returns the team's position for each round in the order the rounds were
+// played, using the last snapshot taken in the round. Snapshots without a round are
+// skipped. Teams are matched ignoring case.
+func TeamHistory(snapshots []Snapshot, team string) []TeamRound {
+	byRound := map[string]TeamRound{}
+	var order []string
+
+	for _, snapshot := range snapshots {
+		if snapshot.Round == "" {
+			continue
+		}
+
+		for _, entry := range snapshot.Entries {
+			if !strings.EqualFold(strings.TrimSpace(entry.Team), strings.TrimSpace(team)) {
+				continue
+			}
+
+			if _, ok := byRound[snapshot.Round]; !ok {
+				order = append(order, snapshot.Round)
+			}
+
+			byRound[snapshot.Round] = TeamRound{
+				Round:  snapshot.Round,
+				Rank:   entry.Rank,
+				Points: entry.Points,
+				Record: entry.Record,
+			}
+		}
+	}
+
+	rounds := make([]TeamRound, 0, len(order))
+	for _, round := range order {
+		rounds = append(rounds, byRound[round])
+	}
+
+	return rounds
+}
+
+// Mover is how far a team has moved since it first appeared in the archive.
+type Mover struct {
+	Team string
+	From int
+	To   int
+}
+
+// Change is the number of places moved, positive when the team moved up.
+func (m Mover) Change() int {
+	return m.From - m.To
+}
+
+// BiggestMovers returns up to n teams that have moved the most places over the season,
+// biggest first. Teams that haven't moved are left out.
+func BiggestMovers(snapshots []Snapshot, n int) []Mover {
+	first := map[string]Mover{}
+	var order []string
+
+	for _, snapshot := range snapshots {
+		for _, entry := range snapshot.Entries {
+			key := strings.ToLower(strings.TrimSpace(entry.Team))
+
+			mover, ok := first[key]
+			if !ok {
+				mover = Mover{Team: entry.Team, From: entry.Rank}
+				order = append(order, key)
+			}
+			mover.To = entry.Rank
+			first[key] = mover
+		}
+	}
+
+	var movers []Mover
+	for _, key := range order {
+		if first[key].Change() != 0 {
+			movers = append(movers, first[key])
+		}
+	}
+
+	sort.SliceStable(movers, func(i, j int) bool {
+		return abs(movers[i].Change()) > abs(movers[j].Change())
+	})
+
+	if len(movers) > n {
+		movers = movers[:n]
+	}
+
+	return movers
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// Report formats the team's round by round history and the season's biggest movers as a
+// discord message.
+func Report(snapshots []Snapshot, team string) string {
+	rounds := TeamHistory(snapshots, team)
+	if len(rounds) == 0 {
+		return fmt.Sprintf("no ladder history found for %s", team)
+	}
+
+	sb := strings.Builder{}
+	sb.WriteString(fmt.Sprintf("History for %s:\n", team))
+	for _, round := range rounds {
+		sb.WriteString(fmt.Sprintf("\tround %s: %s, %s pts (%s)\n", round.Round, vq.Ordinal(round.Rank), formatPoints(round.Points), round.Record))
+	}
+
+	movers := BiggestMovers(snapshots, 3)
+	if len(movers) > 0 {
+		sb.WriteString("Biggest movers this season:\n")
+		for _, mover := range movers {
+			arrow := "↑"
+			if mover.Change() < 0 {
+				arrow = "↓"
+			}
+			sb.WriteString(fmt.Sprintf("\t%s %s%d (%s → %s)\n", mover.Team, arrow, abs(mover.Change()), vq.Ordinal(mover.From), vq.Ordinal(mover.To)))
+		}
+	}
+
+	return sb.String()
+}
+
+func formatPoints(points float64) string {
+	return strconv.FormatFloat(points, 'f', -1, 64)
+}
@@ -0,0 +1,108 @@
+package history_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brewinski/home-lab-server/src/metro-volleyball-bot/history"
+	"github.com/brewinski/home-lab-server/src/metro-volleyball-bot/store"
+	"github.com/brewinski/home-lab-server/src/metro-volleyball-bot/vq"
+)
+
+func standings(round int, teams ...string) []vq.Standing {
+	standings := make([]vq.Standing, 0, len(teams))
+	for i, team := range teams {
+		standings = append(standings, vq.Standing{
+			Team:              team,
+			Rank:              i + 1,
+			CompetitionPoints: float64(round * (len(teams) - i)),
+			Matches:           vq.Record{Played: round},
+		})
+	}
+	return standings
+}
+
+func TestArchive_Record(t *testing.T) {
+	archive := history.NewArchive(store.NewMemory(), "history")
+	start := time.Date(2024, time.February, 5, 22, 0, 0, 0, time.UTC)
+
+	steps := []struct {
+		standings []vq.Standing
+		round     string
+		wantSaved bool
+	}{
+		{standings: standings(1, "Aces", "APG", "Blockers"), round: "1", wantSaved: true},
+		{standings: standings(1, "Aces", "APG", "Blockers"), round: "1", wantSaved: false},
+		{standings: standings(2, "APG", "Aces", "Blockers"), round: "2", wantSaved: true},
+	}
+	for i, step := range steps {
+		saved, err := archive.Record(step.standings, step.round, start.Add(time.Duration(i)*time.Hour))
+		if err != nil {
+			t.Fatalf("Archive.Record() error = %v", err)
+		}
+		if saved != step.wantSaved {
+			t.Errorf("Archive.Record() step %d = %v, want %v", i, saved, step.wantSaved)
+		}
+	}
+
+	snapshots, err := archive.Snapshots()
+	if err != nil {
+		t.Fatalf("Archive.Snapshots() error = %v", err)
+	}
+
+	if len(snapshots) != 2 || snapshots[0].Round != "1" || snapshots[1].Round != "2" || !snapshots[1].Time.Equal(start.Add(2*time.Hour)) {
+		t.Errorf("Archive.Snapshots() = %+v, want the two distinct ladders", snapshots)
+	}
+}
+
+func TestTeamHistory(t *testing.T) {
+	snapshots := []history.Snapshot{
+		history.NewSnapshot(standings(1, "Aces", "APG", "Blockers"), "1", time.Time{}),
+		// a correction later in the same round replaces the earlier ladder.
+		history.NewSnapshot(standings(1, "APG", "Aces", "Blockers"), "1", time.Time{}),
+		history.NewSnapshot(standings(2, "Blockers", "APG", "Aces"), "2", time.Time{}),
+		// a bye in round 3, the round comes from the games not the matches played.
+		history.NewSnapshot(standings(2, "Blockers", "Aces", "APG"), "3", time.Time{}),
+		// archived before the round was recorded.
+		history.NewSnapshot(standings(2, "Aces", "Blockers", "APG"), "", time.Time{}),
+	}
+
+	got := history.TeamHistory(snapshots, "aces")
+	want := []history.TeamRound{
+		{Round: "1", Rank: 2, Points: 2, Record: vq.Record{Played: 1}},
+		{Round: "2", Rank: 3, Points: 2, Record: vq.Record{Played: 2}},
+		{Round: "3", Rank: 2, Points: 4, Record: vq.Record{Played: 2}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("TeamHistory() = %+v, want %+v", got, want)
+	}
+
+	movers := history.BiggestMovers(snapshots[:3], 2)
+	wantMovers := []history.Mover{
+		{Team: "Aces", From: 1, To: 3},
+		{Team: "Blockers", From: 3, To: 1},
+	}
+	if !reflect.DeepEqual(movers, wantMovers) {
+		t.Errorf("BiggestMovers() = %+v, want %+v", movers, wantMovers)
+	}
+}
+
+func TestReport(t *testing.T) {
+	snapshots := []history.Snapshot{
+		history.NewSnapshot(standings(1, "Aces", "APG"), "1", time.Time{}),
+		history.NewSnapshot(standings(2, "APG", "Aces"), "2", time.Time{}),
+	}
+
+	got := history.Report(snapshots, "Aces")
+	for _, want := range []string{"History for Aces:", "round 1: 1st, 2 pts (0-0-0)", "round 2: 2nd, 2 pts", "Aces ↓1 (1st → 2nd)", "APG ↑1 (2nd → 1st)"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Report() = %v, want it to contain %q", got, want)
+		}
+	}
+
+	if got := history.Report(snapshots, "Spike Force"); got != "no ladder history found for Spike Force" {
+		t.Errorf("Report() = %v, want no history", got)
+	}
+}
@@ -1,22 +1,38 @@
 package main
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
-	"reflect"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/brewinski/home-lab-server/src/metro-volleyball-bot/bot"
 	"github.com/brewinski/home-lab-server/src/metro-volleyball-bot/cfg"
+	"github.com/brewinski/home-lab-server/src/metro-volleyball-bot/history"
+	"github.com/brewinski/home-lab-server/src/metro-volleyball-bot/reminder"
+	"github.com/brewinski/home-lab-server/src/metro-volleyball-bot/sim"
+	"github.com/brewinski/home-lab-server/src/metro-volleyball-bot/store"
+	"github.com/brewinski/home-lab-server/src/metro-volleyball-bot/subscription"
 	"github.com/brewinski/home-lab-server/src/metro-volleyball-bot/vq"
 	"github.com/bwmarrin/discordgo"
 )
 
+// Commands are acknowledged straight away and answered when the lookup finishes, give up
+// on slow lookups long before the interaction expires. Autocomplete can't be acknowledged,
+// discord stops waiting after 3 seconds so leave some headroom for sending the choices.
+const (
+	commandTimeout      = 30 * time.Second
+	autocompleteTimeout = 2500 * time.Millisecond
+)
+
 // Variables used for command line parameters
 var (
 	Token                string
@@ -24,6 +40,16 @@ var (
 	PageUrl              string
 	NotificationsChannel string
 	VQClientUrl          string
+	DefaultTeam          string
+	ConfigPath           string
+	CompetitionName      string
+	CacheTTL             time.Duration
+	StateBackend         string
+	StatePath            string
+	OddsEvery            time.Duration
+	ReminderOffsets      string
+	RemindEvery          time.Duration
+	GlobalCommands       bool
 )
 
 func main() {
@@ -33,15 +59,33 @@ func main() {
 	flag.DurationVar(&TickSpeed, "ts", 1*time.Hour, "Page ping frequency as a string duration")
 	// Page URL to monitor
 	flag.StringVar(&PageUrl, "url", PageUrl, "The URL to monitor for changes")
+	// competitions config file, falls back to the built in competition when not set
+	flag.StringVar(&ConfigPath, "config", os.Getenv(cfg.ConfigEnv), "The yaml or json competitions config file. Defaults to $"+cfg.ConfigEnv)
+	// competition from the config file to follow
+	flag.StringVar(&CompetitionName, "competition", "", "The name of the competition to follow, defaults to the first in the config")
 	// VQ Metro Draw Data Base Url
 	flag.StringVar(
 		&VQClientUrl,
 		"draw-url",
-		cfg.VQBaseUrl,
-		"The channel to send notifications",
+		"",
+		"Overrides the base url of the competition being followed",
 	)
+	// how long softr responses are reused before they're requested again. 0 disables caching
+	flag.DurationVar(&CacheTTL, "cache-ttl", 1*time.Minute, "How long VQ responses are cached as a string duration, 0 disables the cache")
+	// team used by commands when one isn't provided
+	flag.StringVar(&DefaultTeam, "team", "", "Overrides the default team of the competition being followed")
+	// where the watchers keep their last seen snapshots between restarts
+	flag.StringVar(&StateBackend, "state-backend", store.BackendFile, "The watcher state store: file, bolt or memory")
+	flag.StringVar(&StatePath, "state", "state", "The directory for the file store, or the database file for the bolt store")
+	// how often the finals odds are posted to the notifications channel
+	flag.DurationVar(&OddsEvery, "odds-every", 0, "How often the finals odds are posted as a string duration, e.g. 168h for weekly. 0 disables the post")
 	// channel to publish notifications to
 	flag.StringVar(&NotificationsChannel, "channel", "volleybot-notifications", "The channel to send notifications")
+	// global commands suit a bot in many guilds, guild commands update instantly
+	flag.BoolVar(&GlobalCommands, "global-commands", false, "Register the commands globally instead of for each guild")
+	// game day reminders for every followed team
+	flag.StringVar(&ReminderOffsets, "reminders", "24h,2h", "How long before each game and duty reminders are posted as a comma separated list of durations. Empty disables reminders")
+	flag.DurationVar(&RemindEvery, "remind-every", 5*time.Minute, "How often reminders are checked for as a string duration")
 	// Parse the flags from the command line
 	flag.Parse()
 
@@ -52,17 +96,99 @@ func main() {
 		slog.String("page", PageUrl),
 	))
 
+	// load the competitions config, everything below depends on it so fail fast.
+	config := cfg.Default()
+	if ConfigPath != "" {
+		loaded, err := cfg.Load(ConfigPath)
+		if err != nil {
+			slog.Error("load config", "error", err, "path", ConfigPath)
+			return
+		}
+		config = loaded
+	}
+
+	competition, err := config.Competition(CompetitionName)
+	if err != nil {
+		slog.Error("select competition", "error", err)
+		return
+	}
+
+	if VQClientUrl != "" {
+		competition.BaseURL = VQClientUrl
+		config = config.WithCompetition(competition)
+	}
+
+	// the base url override isn't checked when the config is loaded.
+	if err := competition.Validate(); err != nil {
+		slog.Error("invalid competition", "error", err, "competition", competition.Name)
+		return
+	}
+
+	// the built in competition has no games ids, the ladder features still work without them.
+	if !competition.HasGames() {
+		slog.Warn("no games endpoint configured, the games commands, fixture watchers and reminders are off until a config file provides one", "competition", competition.Name)
+	}
+
+	if DefaultTeam == "" {
+		DefaultTeam = competition.DefaultTeam
+	}
+
+	offsets, err := reminder.ParseOffsets(ReminderOffsets)
+	if err != nil {
+		slog.Error("parse reminder offsets", "error", err)
+		return
+	}
+
+	slog.Info("following competition", "competition", competition.Name, "division", competition.Division, "team", DefaultTeam)
+
+	// watcher baselines, opened before connecting so a bad volume fails fast.
+	state, err := store.Open(StateBackend, StatePath)
+	if err != nil {
+		slog.Error("open state store", "error", err, "backend", StateBackend, "path", StatePath)
+		return
+	}
+	defer state.Close()
+
+	// every distinct ladder is archived for the history command.
+	archive := history.NewArchive(state, "history/"+competition.Name)
+
+	// what each guild follows, guilds that haven't chosen follow the competition above.
+	subscriptions := subscription.NewRegistry(state, "subscriptions")
+	defaultSubscription := subscription.Subscription{
+		Competition: competition.Name,
+		Division:    competition.Division,
+		Team:        DefaultTeam,
+	}
+
+	// cancelled when the bot shuts down so in-flight requests are abandoned.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
 	// create new http client
 	httpClient := http.Client{
 		Timeout: 10 * time.Second,
 	}
 
-	// volleyball qld client
-	vqClient := vq.NewClient(vq.ClientConfig{
-		Client: &httpClient,
-		ApiUrl: VQClientUrl,
+	// volleyball qld clients, one for each competition and division that's followed
+	clients := newClientPool(config, func(competition cfg.Competition, division string) *vq.Client {
+		return vq.NewClient(vq.ClientConfig{
+			Client:       &httpClient,
+			ApiUrl:       competition.BaseURL,
+			LadderPath:   competition.Ladder.Path(),
+			LadderPageID: competition.Ladder.PageID,
+			GamesPath:    competition.Games.Path(),
+			GamesPageID:  competition.Games.PageID,
+			Division:     division,
+			CacheTTL:     CacheTTL,
+		})
 	})
 
+	vqClient, err := clients.Client(competition.Name, competition.Division)
+	if err != nil {
+		slog.Error("create vq client", "error", err)
+		return
+	}
+
 	// Create a new Discord session using the provided bot token.
 	dg, err := discordgo.New("Bot " + Token)
 	if err != nil {
@@ -72,42 +198,73 @@ func main() {
 	// Cleanly close down the Discord session.
 	defer dg.Close()
 
+	// how the ladder is shown by the ladder command and notifications
+	ladderOptions := bot.LadderEmbedOptions{
+		Title:     "Ladder " + competition.Division,
+		URL:       competition.LadderURL,
+		Highlight: DefaultTeam,
+	}
+
+	// every command the bot answers, registered with discord once connected
+	registry := bot.NewRegistry()
+
 	myBot := bot.New(bot.Config{
 		UpdatesChannel: NotificationsChannel,
 		TickSpeed:      TickSpeed,
 		MonitorUrl:     PageUrl,
+		Subscriptions:  subscriptions,
+		Default:        defaultSubscription,
+		Commands:       registry,
+		GlobalCommands: GlobalCommands,
 	})
 
+	err = registry.Add(commands(commandEnv{
+		vqClient:      vqClient,
+		clients:       clients,
+		subscriptions: subscriptions,
+		defaults:      defaultSubscription,
+		subscription:  myBot.Subscription,
+		archive:       archive,
+		ladderOptions: ladderOptions,
+		games:         config.HasGames(),
+	})...)
+	if err != nil {
+		slog.Error("add commands", "error", err)
+		return
+	}
+
 	// register the bot ready handler
 	dg.AddHandler(myBot.ReadyHandler)
-	// commands handler
-	commandHandler := bot.OnCommandHandlerFactory(func(command string) (string, error) {
-		switch command {
-		case "vb-help":
-			return "no action registered for this command: " + command, nil
-		case "vb-ladder":
-			slog.Info("vb-ladder command received")
-			ladder, err := vqClient.GetLadder()
-			if err != nil {
-				return "", fmt.Errorf("GetLadder unable to get ladder: %w", err)
-			}
+	// set up guilds as they become available, including guilds joined after startup
+	dg.AddHandler(myBot.GuildCreateHandler)
 
-			slog.Info("vb-ladder command response", "ladder", ladder)
+	// commands handler
+	commandHandler := bot.OnCommandHandlerFactory(func(req bot.Request) (bot.Response, error) {
+		ctx, cancel := context.WithTimeout(ctx, commandTimeout)
+		defer cancel()
 
-			return ladder.ToString(), nil
-		case "vb-next-game":
-			slog.Info("vb-next-game command received")
-			return "no action registered for this command: " + command, nil
-		default:
-			// Create the response object
-			return "no action registered for this command" + command, nil
+		response, err := registry.Handle(ctx, req)
+		if errors.Is(err, vq.ErrNoGames) {
+			return bot.Text(noGames), nil
 		}
+
+		return response, err
 	})
 
 	dg.AddHandler(commandHandler)
 
-	// In this example, we only care about receiving message events.
-	dg.Identify.Intents = discordgo.IntentsGuildMessages
+	// suggest teams and divisions from the current ladder as the user types
+	autocompleteHandler := bot.OnAutocompleteHandlerFactory(func(command string, options bot.Options, focused *discordgo.ApplicationCommandInteractionDataOption) ([]*discordgo.ApplicationCommandOptionChoice, error) {
+		ctx, cancel := context.WithTimeout(ctx, autocompleteTimeout)
+		defer cancel()
+
+		return autocompleteChoices(ctx, clients, competition.Name, options, focused)
+	})
+
+	dg.AddHandler(autocompleteHandler)
+
+	// guild events set up guilds the bot joins, message events aren't used yet.
+	dg.Identify.Intents = discordgo.IntentsGuilds | discordgo.IntentsGuildMessages
 
 	// Open a websocket connection to Discord and begin listening.
 	err = dg.Open()
@@ -116,65 +273,299 @@ func main() {
 		return
 	}
 
-	// register volleybot commands
-	bot.RegisterCommands(dg)
+	// a ladder watcher for every followed division and a fixture watcher for every followed
+	// team, created as guilds subscribe.
+	watchers := newTopicJobs(func(topic subscription.Topic) func() {
+		return newWatcher(ctx, clients, topic, state, archive, defaultSubscription, competition.LadderURL, myBot, dg)
+	})
+	checkForChanges := func() {
+		topics, err := myBot.Topics()
+		if err != nil {
+			slog.Error("unable to list followed topics", "error", err)
+			return
+		}
+
+		watchers.run(topics)
+	}
+
+	// a reminder scheduler for every followed team, created as guilds subscribe.
+	reminders := newTopicJobs(func(topic subscription.Topic) func() {
+		return newReminder(ctx, clients, topic, state, offsets, myBot, dg)
+	})
+	checkReminders := func() {
+		topics, err := myBot.Topics()
+		if err != nil {
+			slog.Error("unable to list followed topics", "error", err)
+			return
+		}
+
+		var teams []subscription.Topic
+		for _, topic := range topics {
+			if topic.Team != "" {
+				teams = append(teams, topic)
+			}
+		}
+
+		reminders.run(teams)
+	}
+
+	// reminders are optional, a nil channel never fires.
+	var remindTick <-chan time.Time
+	if len(offsets) > 0 && RemindEvery > 0 {
+		remindTicker := time.NewTicker(RemindEvery)
+		defer remindTicker.Stop()
+		remindTick = remindTicker.C
+	}
+
+	// the odds post is optional, a nil channel never fires. the odds need the fixtures.
+	var oddsTick <-chan time.Time
+	if OddsEvery > 0 && !vqClient.HasGames() {
+		slog.Warn("no games endpoint configured, the odds aren't posted", "competition", competition.Name)
+	} else if OddsEvery > 0 {
+		oddsTicker := time.NewTicker(OddsEvery)
+		defer oddsTicker.Stop()
+		oddsTick = oddsTicker.C
+	}
+
+	divisionTopic := subscription.Topic{Competition: defaultSubscription.Competition, Division: defaultSubscription.Division}
+
+	// each job gets its own ticker, created once, so a frequent job never holds back a
+	// slower one.
+	watchTicker := time.NewTicker(TickSpeed)
+	defer watchTicker.Stop()
 
-	// create ladder changes handler
-	handleLadderChanges := handleLadderChangesFactory(vqClient, myBot, dg)
+	// baseline the watchers straight away rather than an hour after starting.
+	checkForChanges()
 
 	slog.Info("bot is running. press ctrl-c to exit.")
 	sc := make(chan os.Signal, 1)
 	signal.Notify(sc, syscall.SIGINT, syscall.SIGTERM, os.Interrupt, syscall.SIGTERM)
 
+	loop{
+		watch:           watchTicker.C,
+		odds:            oddsTick,
+		remind:          remindTick,
+		checkForChanges: checkForChanges,
+		// the odds are for the whole division, not just guilds following the default team.
+		postOdds:       func() { postOdds(ctx, vqClient, divisionTopic, myBot, dg) },
+		checkReminders: checkReminders,
+	}.run(sc)
+
+	// abandon any requests that are still in flight.
+	// commands are left registered so they keep working across deploys.
+	cancel()
+
+	slog.Info("termination signal received, bot stopping...")
+}
+
+// loop runs the bot's periodic jobs one at a time as their ticks fire, a nil tick never
+// fires. The tickers must be created once outside the loop, a ticker created on each pass
+// restarts whenever another job runs and never fires if that job runs more often.
+type loop struct {
+	watch  <-chan time.Time
+	odds   <-chan time.Time
+	remind <-chan time.Time
+
+	checkForChanges func()
+	postOdds        func()
+	checkReminders  func()
+}
+
+// run runs the jobs until stop receives a signal.
+func (l loop) run(stop <-chan os.Signal) {
 	for {
 		select {
-		case <-time.NewTicker(TickSpeed).C:
-			// monitor the draw pdf page
-			handleLadderChanges()
-		case <-sc:
-			// Wait until CTRL-C or other term signal is received.
-			slog.Info("removing registered commands")
-			err := bot.RemoveCommands(dg)
-			if err != nil {
-				slog.Error("remove commands", "error", err)
-			}
-
-			slog.Info("termination signal received, bot stopping...")
+		case <-l.watch:
+			l.checkForChanges()
+		case <-l.odds:
+			l.postOdds()
+		case <-l.remind:
+			l.checkReminders()
+		case <-stop:
 			return
 		}
 	}
+}
+
+// topicJobs keeps a job for each followed topic. Jobs are created the first time their
+// topic is followed and dropped once nobody follows it.
+type topicJobs struct {
+	create func(subscription.Topic) func()
+	jobs   map[string]func()
+}
+
+func newTopicJobs(create func(subscription.Topic) func()) *topicJobs {
+	return &topicJobs{create: create, jobs: map[string]func(){}}
+}
+
+// run runs the job of every topic, in order.
+func (t *topicJobs) run(topics []subscription.Topic) {
+	jobs := make(map[string]func(), len(topics))
+	for _, topic := range topics {
+		key := topic.String()
+		job, ok := t.jobs[key]
+		if !ok {
+			job = t.create(topic)
+		}
+
+		jobs[key] = job
+	}
+	t.jobs = jobs
+
+	for _, topic := range topics {
+		t.jobs[topic.String()]()
+	}
+}
+
+// newWatcher creates the watcher for the topic, a ladder watcher for division topics and a
+// fixture watcher for team topics. Only the default division's ladder is archived for the
+// history command, and the ladder page is only linked for the default division.
+func newWatcher(ctx context.Context, clients *clientPool, topic subscription.Topic, state store.Store, archive *history.Archive, defaults subscription.Subscription, ladderURL string, b *bot.Bot, s *discordgo.Session) func() {
+	vqClient, err := clients.Client(topic.Competition, topic.Division)
+	if err != nil {
+		slog.Error("unable to watch topic", "error", err, "topic", topic.String())
+		return func() {}
+	}
+
+	if topic.Team != "" {
+		if !vqClient.HasGames() {
+			slog.Warn("no games endpoint configured, fixture changes aren't watched", "topic", topic.String())
+			return func() {}
+		}
+
+		return handleFixtureChangesFactory(ctx, vqClient, topic, state, "fixtures/"+topic.String(), b, s)
+	}
+
+	ladderOptions := bot.LadderEmbedOptions{Title: "Ladder " + topic.Division}
+	if topic.Competition == defaults.Competition && strings.EqualFold(topic.Division, defaults.Division) {
+		ladderOptions.URL = ladderURL
+	} else {
+		archive = nil
+	}
+
+	// snapshots are labelled with the round played, which needs the fixtures.
+	if !vqClient.HasGames() {
+		archive = nil
+	}
 
+	return handleLadderChangesFactory(ctx, vqClient, topic, state, "ladder/"+topic.String(), archive, ladderOptions, b, s)
 }
 
-func handleLadderChangesFactory(vqClient *vq.Client, bot *bot.Bot, s *discordgo.Session) func() {
-	currentLadder, err := vqClient.GetLadder()
+// newReminder returns a func that posts the team's game and duty reminders that are due.
+// Sent reminders are kept in the state store so restarts don't post them again.
+func newReminder(ctx context.Context, clients *clientPool, topic subscription.Topic, state store.Store, offsets []time.Duration, b *bot.Bot, s *discordgo.Session) func() {
+	vqClient, err := clients.Client(topic.Competition, topic.Division)
 	if err != nil {
-		slog.Error("unable to request initial ladder data from server", "error", err)
+		slog.Error("unable to schedule reminders", "error", err, "topic", topic.String())
+		return func() {}
+	}
+
+	if !vqClient.HasGames() {
+		slog.Warn("no games endpoint configured, reminders aren't sent", "topic", topic.String())
+		return func() {}
+	}
+
+	scheduler := reminder.New(reminder.Config{
+		Source:  vqClient,
+		Team:    topic.Team,
+		Offsets: offsets,
+		Store:   state,
+		Key:     "reminders/" + topic.String(),
+	})
+
+	return func() {
+		slog.Info("checking for reminders", "topic", topic.String())
+		sent, err := scheduler.Check(ctx, func(r reminder.Reminder) error {
+			return sendReminder(b, s, topic, r)
+		})
+		if err != nil {
+			slog.Error("unable to send reminders", "error", err, "topic", topic.String())
+		}
+
+		for _, r := range sent {
+			slog.Info("reminder sent", "topic", topic.String(), "kind", r.Kind, "offset", r.Offset.String(), "start", r.Start)
+		}
+	}
+}
+
+// sendReminder posts the reminder to the guilds following the team. It only fails when
+// no guild received it, so a partial failure isn't posted twice.
+func sendReminder(b *bot.Bot, s *discordgo.Session, topic subscription.Topic, r reminder.Reminder) error {
+	messages, errs := b.ChangeHandler(s, topic, r.String())
+
+	for _, err := range errs {
+		slog.Error("reminder message failures", "error", err)
+	}
+
+	if len(messages) == 0 && len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	return nil
+}
+
+func handleLadderChangesFactory(ctx context.Context, vqClient *vq.Client, topic subscription.Topic, state store.Store, key string, archive *history.Archive, ladderOptions bot.LadderEmbedOptions, b *bot.Bot, s *discordgo.Session) func() {
+	var currentLadder vq.GetLadderResponseBody
+	hasBaseline := loadState(state, key, &currentLadder)
+
+	if !hasBaseline {
+		ladder, err := vqClient.GetLadder(ctx)
+		if err != nil {
+			slog.Error("unable to request initial ladder data from server", "error", err)
+		} else {
+			currentLadder, hasBaseline = ladder, true
+			saveState(state, key, currentLadder)
+			archiveLadder(ctx, vqClient, archive, ladder)
+		}
 	}
 
 	return func() {
 		slog.Info("checking for ladder changes")
-		ladderUpdate, err := vqClient.GetLadder()
+		ladderUpdate, err := vqClient.GetLadder(ctx)
 		if err != nil {
 			slog.Error("unable to request ladder data from server", "error", err)
 			return
 		}
 
-		if reflect.DeepEqual(ladderUpdate, currentLadder) {
-			slog.Info("ladder monitor check no changes", "update", ladderUpdate)
+		archiveLadder(ctx, vqClient, archive, ladderUpdate)
+
+		// without a baseline every team would look new, use this ladder as the baseline.
+		if !hasBaseline {
+			currentLadder, hasBaseline = ladderUpdate, true
+			saveState(state, key, currentLadder)
 			return
 		}
 
-		slog.Info("ladder monitor detected changes", "update", ladderUpdate)
+		diff, err := vq.DiffLadderResponses(currentLadder, ladderUpdate)
+		if err != nil {
+			slog.Error("unable to compare ladders", "error", err)
+			return
+		}
+
+		if diff.Empty() {
+			slog.Info("ladder monitor check no changes")
+			return
+		}
 
-		// set the current ladder to the new ladder
+		slog.Info("ladder monitor detected changes", "changes", len(diff.Changes), "added", len(diff.Added), "removed", len(diff.Removed))
+
+		// move the baseline on before broadcasting, in memory and on disk together so a
+		// restart picks up where this check left off. a guild that can't be reached misses
+		// this update rather than blocking the baseline for every guild.
 		currentLadder = ladderUpdate
+		saveState(state, key, currentLadder)
 
-		message := ladderUpdate.ToString()
+		// already parsed by the diff.
+		standings, _ := ladderUpdate.Standings()
 
-		slog.Info("ladder changes handler message", "message", message)
+		slog.Info("ladder changes handler message", "message", diff.String())
 
-		messages, errs := bot.ChangeHandler(s, message)
+		// highlight the team each guild follows.
+		messages, errs := b.Broadcast(s, topic, func(sub subscription.Subscription) bot.Response {
+			opts := ladderOptions
+			opts.Highlight = sub.Team
+			return bot.LadderUpdateResponse(diff, standings, opts)
+		})
 
 		// log any errors that occurred
 		for _, err := range errs {
@@ -187,3 +578,428 @@ func handleLadderChangesFactory(vqClient *vq.Client, bot *bot.Bot, s *discordgo.
 		}
 	}
 }
+
+func handleFixtureChangesFactory(ctx context.Context, vqClient *vq.Client, topic subscription.Topic, state store.Store, key string, bot *bot.Bot, s *discordgo.Session) func() {
+	team := topic.Team
+
+	var currentGames []vq.GameRecord
+	hasBaseline := loadState(state, key, &currentGames)
+
+	if !hasBaseline {
+		games, err := vqClient.AllGamesByTeam(ctx, team)
+		if err != nil {
+			slog.Error("unable to request initial fixtures from server", "error", err, "team", team)
+		} else {
+			currentGames, hasBaseline = games, true
+			saveState(state, key, currentGames)
+		}
+	}
+
+	return func() {
+		slog.Info("checking for fixture changes", "team", team)
+		gamesUpdate, err := vqClient.AllGamesByTeam(ctx, team)
+		if err != nil {
+			slog.Error("unable to request fixtures from server", "error", err, "team", team)
+			return
+		}
+
+		// without a baseline every game would look new, use these games as the baseline.
+		if !hasBaseline {
+			currentGames, hasBaseline = gamesUpdate, true
+			saveState(state, key, currentGames)
+			return
+		}
+
+		diff := vq.DiffFixtures(currentGames, gamesUpdate)
+		if diff.Empty() {
+			slog.Info("fixture monitor check no changes", "team", team)
+			return
+		}
+
+		slog.Info("fixture monitor detected changes", "team", team, "changes", len(diff.Changes), "added", len(diff.Added), "removed", len(diff.Removed))
+
+		// saved with the in memory baseline, see the ladder watcher.
+		currentGames = gamesUpdate
+		saveState(state, key, currentGames)
+
+		message := fmt.Sprintf("Fixture update for %s:\n%s", team, diff.String())
+
+		messages, errs := bot.ChangeHandler(s, topic, message)
+
+		for _, err := range errs {
+			slog.Error("fixture changes handler message failures", "error", err)
+		}
+
+		for _, message := range messages {
+			slog.Info("fixture changes handler message successes", "message", message)
+		}
+	}
+}
+
+// headToHeadReply formats the record between the two teams and their next meeting.
+func headToHeadReply(ctx context.Context, vqClient *vq.Client, teamA, teamB string, now time.Time) (string, error) {
+	if teamB == "" {
+		return "choose a team to compare against", nil
+	}
+
+	games, err := vqClient.AllGamesBetween(ctx, teamA, teamB)
+	if err != nil {
+		return "", fmt.Errorf("AllGamesBetween unable to get games: %w", err)
+	}
+
+	if len(games) == 0 {
+		return fmt.Sprintf("no games found between %s and %s", teamA, teamB), nil
+	}
+
+	return vq.NewHeadToHead(games, teamA, teamB, now).String(), nil
+}
+
+// oddsReply simulates the rest of the season from the current ladder and fixtures.
+func oddsReply(ctx context.Context, vqClient *vq.Client, now time.Time) (string, error) {
+	ladder, err := vqClient.GetLadder(ctx)
+	if err != nil {
+		return "", fmt.Errorf("GetLadder unable to get ladder: %w", err)
+	}
+
+	standings, err := ladder.Standings()
+	if err != nil {
+		return "", fmt.Errorf("Standings unable to parse ladder: %w", err)
+	}
+
+	scheme, err := ladder.PointsScheme()
+	if err != nil {
+		return "", fmt.Errorf("PointsScheme unable to parse ladder: %w", err)
+	}
+
+	games, err := vqClient.AllGames(ctx)
+	if err != nil {
+		return "", fmt.Errorf("AllGames unable to get games: %w", err)
+	}
+
+	result, err := sim.Simulate(standings, games, scheme, now, sim.Options{})
+	if err != nil {
+		return "", fmt.Errorf("Simulate unable to simulate the season: %w", err)
+	}
+
+	return sim.Report(result), nil
+}
+
+// postOdds posts the finals odds to the notifications channel.
+func postOdds(ctx context.Context, vqClient *vq.Client, topic subscription.Topic, bot *bot.Bot, s *discordgo.Session) {
+	message, err := oddsReply(ctx, vqClient, time.Now())
+	if err != nil {
+		slog.Error("unable to simulate finals odds", "error", err)
+		return
+	}
+
+	messages, errs := bot.ChangeHandler(s, topic, message)
+
+	for _, err := range errs {
+		slog.Error("odds post message failures", "error", err)
+	}
+
+	for _, message := range messages {
+		slog.Info("odds post message successes", "message", message)
+	}
+}
+
+// archiveLadder adds the ladder to the history if it's changed since the last snapshot.
+// Only the default division is archived, other divisions' watchers have no archive. The
+// snapshot is labelled with the latest round played, so it isn't archived when the games
+// can't be loaded, the next check will try again.
+func archiveLadder(ctx context.Context, vqClient *vq.Client, archive *history.Archive, ladder vq.GetLadderResponseBody) {
+	if archive == nil {
+		return
+	}
+
+	standings, err := ladder.Standings()
+	if err != nil {
+		slog.Error("unable to archive ladder", "error", err)
+		return
+	}
+
+	games, err := vqClient.AllGames(ctx)
+	if err != nil {
+		slog.Error("unable to archive ladder, games unavailable", "error", err)
+		return
+	}
+
+	// before the first round is played there's no history to show.
+	round, played := vq.CompletedRound(games)
+	if !played {
+		return
+	}
+
+	saved, err := archive.Record(standings, round, time.Now())
+	if err != nil {
+		slog.Error("unable to archive ladder", "error", err)
+		return
+	}
+
+	if saved {
+		slog.Info("archived ladder snapshot", "round", round)
+	}
+}
+
+// historyReply formats the team's ladder history.
+func historyReply(archive *history.Archive, team string) (string, error) {
+	snapshots, err := archive.Snapshots()
+	if err != nil {
+		return "", fmt.Errorf("Snapshots unable to load ladder history: %w", err)
+	}
+
+	return history.Report(snapshots, team), nil
+}
+
+// loadState reads a watcher's saved snapshot into v. It returns false when there isn't
+// one or it can't be read.
+func loadState(state store.Store, key string, v any) bool {
+	found, err := store.GetJSON(state, key, v)
+	if err != nil {
+		slog.Error("unable to load saved state", "error", err, "key", key)
+		return false
+	}
+
+	if found {
+		slog.Info("loaded saved state", "key", key)
+	}
+
+	return found
+}
+
+// saveState writes a watcher's snapshot. Failures are logged, the watcher keeps going with
+// its in memory copy.
+func saveState(state store.Store, key string, v any) {
+	if err := store.PutJSON(state, key, v); err != nil {
+		slog.Error("unable to save state", "error", err, "key", key)
+	}
+}
+
+// ladderReply renders the current ladder of the division.
+func ladderReply(ctx context.Context, vqClient *vq.Client, division string, opts bot.LadderEmbedOptions) (bot.Response, error) {
+	ladder, err := vqClient.GetDivisionLadder(ctx, division)
+	if err != nil {
+		return bot.Response{}, fmt.Errorf("GetDivisionLadder unable to get ladder: %w", err)
+	}
+
+	if len(ladder.Records) == 0 {
+		return bot.Text(fmt.Sprintf("no teams found in division %s", division)), nil
+	}
+
+	standings, err := ladder.Standings()
+	if err != nil {
+		return bot.Response{}, fmt.Errorf("Standings unable to parse ladder: %w", err)
+	}
+
+	return bot.Response{Embeds: bot.LadderEmbeds(standings, opts)}, nil
+}
+
+// fairnessReply compares the timeslots and duties given to each team on the ladder.
+func fairnessReply(ctx context.Context, vqClient *vq.Client) (string, error) {
+	ladder, err := vqClient.GetLadder(ctx)
+	if err != nil {
+		return "", fmt.Errorf("GetLadder unable to get ladder: %w", err)
+	}
+
+	loads, err := ladder.Loads()
+	if err != nil {
+		return "", fmt.Errorf("Loads unable to read ladder: %w", err)
+	}
+
+	return vq.Fairness(loads).String(), nil
+}
+
+// exportReply renders the ladder or the division's fixtures in the format and attaches
+// the result as a file.
+func exportReply(ctx context.Context, vqClient *vq.Client, format, data string) (bot.Response, error) {
+	renderer, err := vq.LookupRenderer(format)
+	if errors.Is(err, vq.ErrUnknownFormat) {
+		return bot.Text(fmt.Sprintf("unknown format %s, choose one of: %s", format, strings.Join(vq.Formats(), ", "))), nil
+	}
+	if err != nil {
+		return bot.Response{}, fmt.Errorf("LookupRenderer unable to find renderer: %w", err)
+	}
+
+	buf := bytes.Buffer{}
+	switch data {
+	case "ladder":
+		ladder, err := vqClient.GetLadder(ctx)
+		if err != nil {
+			return bot.Response{}, fmt.Errorf("GetLadder unable to get ladder: %w", err)
+		}
+
+		if err := renderer.RenderLadder(&buf, ladder); err != nil {
+			return bot.Response{}, fmt.Errorf("RenderLadder unable to export ladder: %w", err)
+		}
+	case "fixtures":
+		ladder, err := vqClient.GetLadder(ctx)
+		if err != nil {
+			return bot.Response{}, fmt.Errorf("GetLadder unable to get ladder: %w", err)
+		}
+
+		games, err := vqClient.AllGames(ctx)
+		if err != nil {
+			return bot.Response{}, fmt.Errorf("AllGames unable to get games: %w", err)
+		}
+
+		if err := renderer.RenderFixtures(&buf, vq.DivisionGames(games, ladder)); err != nil {
+			return bot.Response{}, fmt.Errorf("RenderFixtures unable to export fixtures: %w", err)
+		}
+	default:
+		return bot.Text(fmt.Sprintf("unknown export %s, choose ladder or fixtures", data)), nil
+	}
+
+	return bot.Response{
+		Content: fmt.Sprintf("%s export (%s)", data, format),
+		Files: []bot.Attachment{{
+			Name:        data + "." + renderer.Extension(),
+			ContentType: renderer.ContentType(),
+			Data:        buf.Bytes(),
+		}},
+	}, nil
+}
+
+// autocompleteChoices suggests values for the option the user is typing in. Divisions and
+// teams come from the competition chosen in the same command, or the followed competition,
+// and teams from the chosen division's ladder.
+func autocompleteChoices(ctx context.Context, clients *clientPool, defaultCompetition string, options bot.Options, focused *discordgo.ApplicationCommandInteractionDataOption) ([]*discordgo.ApplicationCommandOptionChoice, error) {
+	typed := focused.StringValue()
+
+	if focused.Name == "competition" {
+		return bot.Choices(clients.Competitions(), typed), nil
+	}
+
+	vqClient, err := clients.Client(options.String("competition", defaultCompetition), options.String("division", ""))
+	if err != nil {
+		// the competition is still being typed, there's nothing to suggest yet.
+		return nil, nil
+	}
+
+	switch focused.Name {
+	case "division":
+		divisions, err := vqClient.Divisions(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("Divisions unable to get divisions: %w", err)
+		}
+
+		return bot.Choices(divisions, typed), nil
+	case "team", "team_a", "team_b":
+		ladder, err := vqClient.GetLadder(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("GetLadder unable to get ladder: %w", err)
+		}
+
+		teams := make([]string, 0, len(ladder.Records))
+		for _, record := range ladder.Records {
+			teams = append(teams, record.Fields.TeamName)
+		}
+
+		return bot.Choices(teams, typed), nil
+	default:
+		return nil, nil
+	}
+}
+
+// subscribeReply saves what the guild follows. Options the user leaves out are taken from
+// the default subscription, and updates go to the channel the command was run in unless
+// another is chosen. The division and team are checked against the ladder.
+func subscribeReply(ctx context.Context, clients *clientPool, subscriptions *subscription.Registry, defaults subscription.Subscription, req bot.Request) (string, error) {
+	if req.GuildID == "" {
+		return "subscriptions can only be set up in a server", nil
+	}
+
+	sub := subscription.Subscription{
+		GuildID:     req.GuildID,
+		ChannelID:   req.Options.Channel("channel", req.ChannelID),
+		Competition: req.Options.String("competition", defaults.Competition),
+		Division:    req.Options.String("division", defaults.Division),
+		Team:        req.Options.String("team", ""),
+	}
+
+	vqClient, err := clients.Client(sub.Competition, sub.Division)
+	if err != nil {
+		return fmt.Sprintf("unknown competition %s, choose one of: %s", sub.Competition, strings.Join(clients.Competitions(), ", ")), nil
+	}
+
+	ladder, err := vqClient.GetLadder(ctx)
+	if err != nil {
+		return "", fmt.Errorf("GetLadder unable to get ladder: %w", err)
+	}
+
+	if len(ladder.Records) == 0 {
+		return fmt.Sprintf("no teams found in division %s", sub.Division), nil
+	}
+
+	// use the names as they're written on the ladder.
+	sub.Division = ladder.Records[0].Fields.Division
+	if sub.Team != "" {
+		found := false
+		for _, record := range ladder.Records {
+			if strings.EqualFold(strings.TrimSpace(record.Fields.TeamName), sub.Team) {
+				sub.Team, found = record.Fields.TeamName, true
+				break
+			}
+		}
+
+		if !found {
+			return fmt.Sprintf("no team named %s in division %s", sub.Team, sub.Division), nil
+		}
+	}
+
+	if err := subscriptions.Subscribe(sub); err != nil {
+		return "", fmt.Errorf("Subscribe unable to save subscription: %w", err)
+	}
+
+	return "subscribed, " + sub.String(), nil
+}
+
+// unsubscribeReply stops the guild's updates.
+func unsubscribeReply(subscriptions *subscription.Registry, guildID string) (string, error) {
+	if guildID == "" {
+		return "subscriptions can only be set up in a server", nil
+	}
+
+	removed, err := subscriptions.Unsubscribe(guildID)
+	if err != nil {
+		return "", fmt.Errorf("Unsubscribe unable to save subscription: %w", err)
+	}
+
+	if !removed {
+		return "this server isn't subscribed to any updates", nil
+	}
+
+	return "unsubscribed, updates will no longer be posted to this server", nil
+}
+
+// text wraps a plain text reply as a response.
+func text(message string, err error) (bot.Response, error) {
+	return bot.Text(message), err
+}
+
+// nextGameReply looks up the team's first game after now and formats the reply.
+func nextGameReply(ctx context.Context, vqClient *vq.Client, team string, now time.Time) (string, error) {
+	games, err := vqClient.AllGamesByTeam(ctx, team)
+	if err != nil {
+		return "", fmt.Errorf("AllGamesByTeam unable to get games: %w", err)
+	}
+
+	game, start, found := vq.NextGame(games, now)
+	if !found {
+		return fmt.Sprintf("no upcoming games found for %s", team), nil
+	}
+
+	return nextGameMessage(team, game, start), nil
+}
+
+// nextGameMessage formats the next game for a team as a discord message.
+func nextGameMessage(team string, game vq.GameRecord, start time.Time) string {
+	sb := strings.Builder{}
+	sb.WriteString(fmt.Sprintf("Next game for %s: <t:%d:R>\n", team, start.Unix()))
+	sb.WriteString(fmt.Sprintf("\topponent: %s\n", game.Opponent(team)))
+	sb.WriteString(fmt.Sprintf("\tvenue: %s\n", game.Fields.Venue))
+	sb.WriteString(fmt.Sprintf("\tcourt: %s\n", game.Fields.Court))
+	sb.WriteString(fmt.Sprintf("\tround: %s\n", game.Fields.Round))
+	sb.WriteString(fmt.Sprintf("\ttime: <t:%d:F>\n", start.Unix()))
+
+	return sb.String()
+}
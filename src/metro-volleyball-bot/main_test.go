@@ -1,6 +1,23 @@
 package main
 
-import "testing"
+import (
+	"context"
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brewinski/home-lab-server/src/metro-volleyball-bot/bot"
+	"github.com/brewinski/home-lab-server/src/metro-volleyball-bot/cfg"
+	"github.com/brewinski/home-lab-server/src/metro-volleyball-bot/history"
+	"github.com/brewinski/home-lab-server/src/metro-volleyball-bot/store"
+	"github.com/brewinski/home-lab-server/src/metro-volleyball-bot/subscription"
+	"github.com/brewinski/home-lab-server/src/metro-volleyball-bot/vq"
+	"github.com/brewinski/home-lab-server/src/metro-volleyball-bot/vq/vqtest"
+	"github.com/bwmarrin/discordgo"
+)
 
 func Test_main(t *testing.T) {
 	tests := []struct {
@@ -14,3 +31,545 @@ func Test_main(t *testing.T) {
 		})
 	}
 }
+
+func Test_nextGameReply(t *testing.T) {
+	tests := []struct {
+		name         string
+		team         string
+		now          time.Time
+		failures     []vqtest.Failure
+		wantContains []string
+		wantErr      bool
+	}{
+		{
+			name:         "reply with the first game after now",
+			team:         "aces",
+			now:          time.Date(2024, time.February, 13, 0, 0, 0, 0, time.UTC),
+			wantContains: []string{"Next game for aces", "opponent: Dig Deep", "round: 3", "court: Court 1"},
+		},
+		{
+			name:         "reply when the season is over",
+			team:         "aces",
+			now:          time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC),
+			wantContains: []string{"no upcoming games found for aces"},
+		},
+		{
+			name:     "return an error when the games can't be loaded",
+			team:     "aces",
+			now:      time.Date(2024, time.February, 13, 0, 0, 0, 0, time.UTC),
+			failures: []vqtest.Failure{vqtest.MalformedJSON},
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := vqtest.NewServer(t)
+			s.Fail(vqtest.Games, tt.failures...)
+
+			got, err := nextGameReply(context.Background(), s.NewClient(), tt.team, tt.now)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("nextGameReply() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			for _, want := range tt.wantContains {
+				if !strings.Contains(got, want) {
+					t.Errorf("nextGameReply() = %v, want it to contain %q", got, want)
+				}
+			}
+		})
+	}
+}
+
+func Test_oddsReply(t *testing.T) {
+	s := vqtest.NewServer(t)
+
+	got, err := oddsReply(context.Background(), s.NewClient(), time.Date(2024, time.February, 20, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("oddsReply() error = %v", err)
+	}
+
+	for _, want := range []string{"with 6 games left", "Net Results", "Aces"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("oddsReply() = %v, want it to contain %q", got, want)
+		}
+	}
+}
+
+func Test_headToHeadReply(t *testing.T) {
+	tests := []struct {
+		name         string
+		teamA        string
+		teamB        string
+		wantContains string
+	}{
+		{name: "played and upcoming", teamA: "aces", teamB: "spike force", wantContains: "record: aces 1, spike force 0"},
+		{name: "never played", teamA: "aces", teamB: "setters", wantContains: "no games found between aces and setters"},
+		{name: "missing opponent", teamA: "aces", wantContains: "choose a team"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := vqtest.NewServer(t)
+
+			got, err := headToHeadReply(context.Background(), s.NewClient(), tt.teamA, tt.teamB, time.Date(2024, time.February, 20, 0, 0, 0, 0, time.UTC))
+			if err != nil {
+				t.Fatalf("headToHeadReply() error = %v", err)
+			}
+
+			if !strings.Contains(got, tt.wantContains) {
+				t.Errorf("headToHeadReply() = %v, want it to contain %q", got, tt.wantContains)
+			}
+		})
+	}
+}
+
+func Test_ladderReply(t *testing.T) {
+	s := vqtest.NewServer(t)
+
+	got, err := ladderReply(context.Background(), s.NewClient(), vqtest.Division, bot.LadderEmbedOptions{Title: "Ladder MD", Highlight: "aces"})
+	if err != nil {
+		t.Fatalf("ladderReply() error = %v", err)
+	}
+
+	if len(got.Embeds) != 1 {
+		t.Fatalf("ladderReply() = %d embeds, want 1", len(got.Embeds))
+	}
+
+	description := got.Embeds[0].Description
+	for _, want := range []string{"1 Net Results", "» ", "Aces", "Pts"} {
+		if !strings.Contains(description, want) {
+			t.Errorf("ladderReply() description = %v, want it to contain %q", description, want)
+		}
+	}
+
+	if strings.Contains(description, "Setters") {
+		t.Errorf("ladderReply() description = %v, want only the MD division", description)
+	}
+}
+
+func Test_ladderReply_Pools(t *testing.T) {
+	s := vqtest.NewServer(t)
+
+	got, err := ladderReply(context.Background(), s.NewClient(), vqtest.Division, bot.LadderEmbedOptions{Title: "Ladder MD", Highlight: "aces", View: vq.ViewPools})
+	if err != nil {
+		t.Fatalf("ladderReply() error = %v", err)
+	}
+
+	if len(got.Embeds) != 2 || got.Embeds[0].Title != "Ladder MD · Pool A (Pool Play)" || got.Embeds[1].Title != "Ladder MD · Pool B (Pool Play)" {
+		t.Fatalf("ladderReply() = %+v, want an embed for each pool", got.Embeds)
+	}
+
+	if !strings.Contains(got.Embeds[0].Description, "»   3 Aces") {
+		t.Errorf("ladderReply() pool A = %v, want Aces 3rd in the pool", got.Embeds[0].Description)
+	}
+
+	if footer := got.Embeds[1].Footer; footer == nil || footer.Text != "» aces" {
+		t.Errorf("ladderReply() footer = %+v, want the followed team only", footer)
+	}
+}
+
+func Test_exportReply(t *testing.T) {
+	s := vqtest.NewServer(t)
+	// the games table holds every division's games.
+	s.SetGames(append(vqtest.GamesFixture(t), vq.GameRecord{ID: "wd1", Fields: vq.GameFields{Round: "1", TeamA: "Setters", TeamB: "Block Party"}}))
+
+	tests := []struct {
+		name         string
+		format       string
+		data         string
+		wantFile     string
+		wantContains string
+		wantMissing  string
+		wantText     string
+	}{
+		{name: "ladder csv", format: "csv", data: "ladder", wantFile: "ladder.csv", wantContains: "Net Results"},
+		{name: "fixtures markdown", format: "markdown", data: "fixtures", wantFile: "fixtures.md", wantContains: "| Round |", wantMissing: "Setters"},
+		{name: "ladder html", format: "html", data: "ladder", wantFile: "ladder.html", wantContains: "<title>Ladder MD</title>"},
+		{name: "unknown format", format: "xlsx", data: "ladder", wantText: "unknown format xlsx, choose one of: csv, html, json, markdown"},
+		{name: "unknown data", format: "json", data: "players", wantText: "unknown export players, choose ladder or fixtures"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := exportReply(context.Background(), s.NewClient(), tt.format, tt.data)
+			if err != nil {
+				t.Fatalf("exportReply() error = %v", err)
+			}
+
+			if tt.wantText != "" {
+				if got.Content != tt.wantText || len(got.Files) != 0 {
+					t.Errorf("exportReply() = %v, want %v", got, tt.wantText)
+				}
+				return
+			}
+
+			if len(got.Files) != 1 || got.Files[0].Name != tt.wantFile {
+				t.Fatalf("exportReply() files = %v, want %v", got.Files, tt.wantFile)
+			}
+
+			if !strings.Contains(string(got.Files[0].Data), tt.wantContains) {
+				t.Errorf("exportReply() file = %s, want it to contain %q", got.Files[0].Data, tt.wantContains)
+			}
+
+			if tt.wantMissing != "" && strings.Contains(string(got.Files[0].Data), tt.wantMissing) {
+				t.Errorf("exportReply() file = %s, want it to leave out %q", got.Files[0].Data, tt.wantMissing)
+			}
+		})
+	}
+}
+
+func Test_fairnessReply(t *testing.T) {
+	s := vqtest.NewServer(t)
+
+	got, err := fairnessReply(context.Background(), s.NewClient())
+	if err != nil {
+		t.Fatalf("fairnessReply() error = %v", err)
+	}
+
+	for _, want := range []string{
+		"Aces           5+    0     0-    5+",
+		"Aces 6:30: 5 (mean 1.7, sd 1.5), more than most",
+		"Aces duties: 5 (mean 2.5, sd 1.1), more than most",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("fairnessReply() = %v, want it to contain %q", got, want)
+		}
+	}
+
+	if strings.Contains(got, "Setters") {
+		t.Errorf("fairnessReply() = %v, want only the MD division", got)
+	}
+}
+
+func Test_ladderReply_Division(t *testing.T) {
+	s := vqtest.NewServer(t)
+
+	got, err := ladderReply(context.Background(), s.NewClient(), "WD", bot.LadderEmbedOptions{Title: "Ladder WD"})
+	if err != nil {
+		t.Fatalf("ladderReply() error = %v", err)
+	}
+
+	if len(got.Embeds) != 1 || !strings.Contains(got.Embeds[0].Description, "Setters") || strings.Contains(got.Embeds[0].Description, "Aces") {
+		t.Errorf("ladderReply() = %+v, want only the WD division", got.Embeds)
+	}
+
+	got, err = ladderReply(context.Background(), s.NewClient(), "XD", bot.LadderEmbedOptions{Title: "Ladder XD"})
+	if err != nil || got.Content != "no teams found in division XD" {
+		t.Errorf("ladderReply() = %v, %v, want no teams found", got, err)
+	}
+}
+
+func Test_autocompleteChoices(t *testing.T) {
+	s := vqtest.NewServer(t)
+
+	option := func(name, value string, focused bool) *discordgo.ApplicationCommandInteractionDataOption {
+		return &discordgo.ApplicationCommandInteractionDataOption{Name: name, Type: discordgo.ApplicationCommandOptionString, Value: value, Focused: focused}
+	}
+
+	tests := []struct {
+		name    string
+		options []*discordgo.ApplicationCommandInteractionDataOption
+		want    []string
+	}{
+		{name: "teams in the followed division", options: []*discordgo.ApplicationCommandInteractionDataOption{option("team", "b", true)}, want: []string{"Blockers"}},
+		{name: "teams in the chosen division", options: []*discordgo.ApplicationCommandInteractionDataOption{option("division", "WD", false), option("team", "b", true)}, want: []string{"Block Party"}},
+		{name: "team b", options: []*discordgo.ApplicationCommandInteractionDataOption{option("team_b", "", true)}, want: []string{"Aces", "APG", "Blockers", "Dig Deep", "Net Results", "Spike Force"}},
+		{name: "divisions", options: []*discordgo.ApplicationCommandInteractionDataOption{option("division", "", true)}, want: []string{"MD", "WD"}},
+		{name: "competitions", options: []*discordgo.ApplicationCommandInteractionDataOption{option("competition", "vq", true)}, want: []string{"vqtest"}},
+		{name: "unknown competition", options: []*discordgo.ApplicationCommandInteractionDataOption{option("competition", "vqt", false), option("team", "", true)}, want: nil},
+		{name: "other options", options: []*discordgo.ApplicationCommandInteractionDataOption{option("view", "", true)}, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := bot.ParseOptions(tt.options)
+			focused, _ := options.Focused()
+
+			choices, err := autocompleteChoices(context.Background(), testClients(s), "vqtest", options, focused)
+			if err != nil {
+				t.Fatalf("autocompleteChoices() error = %v", err)
+			}
+
+			var got []string
+			for _, choice := range choices {
+				got = append(got, choice.Name)
+			}
+
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("autocompleteChoices() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// testClients is a client pool with a single competition served by s.
+func testClients(s *vqtest.Server) *clientPool {
+	config := cfg.Config{Competitions: []cfg.Competition{{Name: "vqtest", Division: vqtest.Division}}}
+
+	return newClientPool(config, func(_ cfg.Competition, division string) *vq.Client {
+		clientConfig := s.ClientConfig()
+		clientConfig.Division = division
+		return vq.NewClient(clientConfig)
+	})
+}
+
+func Test_subscribeReply(t *testing.T) {
+	s := vqtest.NewServer(t)
+	defaults := subscription.Subscription{Competition: "vqtest", Division: vqtest.Division, Team: "Aces"}
+
+	option := func(name, value string) *discordgo.ApplicationCommandInteractionDataOption {
+		return &discordgo.ApplicationCommandInteractionDataOption{Name: name, Type: discordgo.ApplicationCommandOptionString, Value: value}
+	}
+	channel := &discordgo.ApplicationCommandInteractionDataOption{Name: "channel", Type: discordgo.ApplicationCommandOptionChannel, Value: "c2"}
+
+	tests := []struct {
+		name    string
+		guildID string
+		options []*discordgo.ApplicationCommandInteractionDataOption
+		want    string
+		wantSub *subscription.Subscription
+	}{
+		{
+			name:    "defaults to this channel and the followed division",
+			guildID: "g1",
+			want:    "subscribed, following vqtest division MD in <#c1>",
+			wantSub: &subscription.Subscription{GuildID: "g1", ChannelID: "c1", Competition: "vqtest", Division: "MD"},
+		},
+		{
+			name:    "chosen channel, division and team",
+			guildID: "g1",
+			options: []*discordgo.ApplicationCommandInteractionDataOption{channel, option("division", "wd"), option("team", "setters")},
+			want:    "subscribed, following vqtest division WD and team Setters in <#c2>",
+			wantSub: &subscription.Subscription{GuildID: "g1", ChannelID: "c2", Competition: "vqtest", Division: "WD", Team: "Setters"},
+		},
+		{name: "unknown team", guildID: "g2", options: []*discordgo.ApplicationCommandInteractionDataOption{option("team", "Aces"), option("division", "WD")}, want: "no team named Aces in division WD"},
+		{name: "unknown division", guildID: "g2", options: []*discordgo.ApplicationCommandInteractionDataOption{option("division", "XD")}, want: "no teams found in division XD"},
+		{name: "unknown competition", guildID: "g2", options: []*discordgo.ApplicationCommandInteractionDataOption{option("competition", "vqmetro19")}, want: "unknown competition vqmetro19, choose one of: vqtest"},
+		{name: "direct message", guildID: "", want: "subscriptions can only be set up in a server"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			subscriptions := subscription.NewRegistry(store.NewMemory(), "subscriptions")
+			req := bot.Request{Command: "vb-subscribe", Options: bot.ParseOptions(tt.options), GuildID: tt.guildID, ChannelID: "c1"}
+
+			got, err := subscribeReply(context.Background(), testClients(s), subscriptions, defaults, req)
+			if err != nil {
+				t.Fatalf("subscribeReply() error = %v", err)
+			}
+
+			if got != tt.want {
+				t.Errorf("subscribeReply() = %q, want %q", got, tt.want)
+			}
+
+			sub, found, _ := subscriptions.Get("g1")
+			if tt.wantSub != nil && (!found || sub != *tt.wantSub) {
+				t.Errorf("subscribeReply() saved %+v, want %+v", sub, *tt.wantSub)
+			}
+			if all, _ := subscriptions.All(); tt.wantSub == nil && len(all) != 0 {
+				t.Errorf("subscribeReply() saved %+v, want nothing saved", all)
+			}
+		})
+	}
+}
+
+func Test_unsubscribeReply(t *testing.T) {
+	subscriptions := subscription.NewRegistry(store.NewMemory(), "subscriptions")
+	subscriptions.Subscribe(subscription.Subscription{GuildID: "g1", Competition: "vqtest", Division: "MD"})
+
+	steps := []string{
+		"unsubscribed, updates will no longer be posted to this server",
+		"this server isn't subscribed to any updates",
+	}
+	for _, want := range steps {
+		got, err := unsubscribeReply(subscriptions, "g1")
+		if err != nil || got != want {
+			t.Errorf("unsubscribeReply() = %q, %v, want %q", got, err, want)
+		}
+	}
+}
+
+func Test_commands(t *testing.T) {
+	s := vqtest.NewServer(t)
+	defaults := subscription.Subscription{Competition: "vqtest", Division: vqtest.Division, Team: "Aces"}
+
+	subscriptions := subscription.NewRegistry(store.NewMemory(), "subscriptions")
+	subscriptions.Subscribe(subscription.Subscription{GuildID: "follows-team", Competition: "vqtest", Division: vqtest.Division, Team: "Dig Deep"})
+	subscriptions.Subscribe(subscription.Subscription{GuildID: "no-team", Competition: "vqtest", Division: vqtest.Division})
+	subscriptions.Unsubscribe("unsubscribed")
+
+	registry := bot.NewRegistry()
+	err := registry.Add(commands(commandEnv{
+		vqClient:      s.NewClient(),
+		clients:       testClients(s),
+		subscriptions: subscriptions,
+		defaults:      defaults,
+		subscription:  bot.New(bot.Config{Subscriptions: subscriptions, Default: defaults}).Subscription,
+		ladderOptions: bot.LadderEmbedOptions{Title: "Ladder MD"},
+		games:         true,
+	})...)
+	if err != nil {
+		t.Fatalf("Registry.Add() error = %v", err)
+	}
+
+	// every command is registered with discord and described by the help command.
+	help, err := registry.Handle(context.Background(), bot.Request{Command: bot.HelpCommand})
+	if err != nil || len(help.Embeds) == 0 {
+		t.Fatalf("Registry.Handle() = %v, %v, want the help embeds", help, err)
+	}
+
+	for _, command := range registry.ApplicationCommands() {
+		if !strings.Contains(help.Embeds[0].Description, "**/"+command.Name) {
+			t.Errorf("help = %q, want it to describe %s", help.Embeds[0].Description, command.Name)
+		}
+	}
+
+	// the options are passed to the command's handler.
+	req := bot.Request{Command: "vb-next-game", Options: bot.ParseOptions([]*discordgo.ApplicationCommandInteractionDataOption{
+		{Name: "team", Type: discordgo.ApplicationCommandOptionString, Value: "Dig Deep"},
+	})}
+	got, err := registry.Handle(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Registry.Handle() error = %v", err)
+	}
+
+	if !strings.Contains(got.Content, "Dig Deep") {
+		t.Errorf("Registry.Handle() = %q, want the next game for Dig Deep", got.Content)
+	}
+
+	// left out options fall back to what the guild follows.
+	tests := []struct {
+		name    string
+		guildID string
+		command string
+		want    string
+	}{
+		{name: "guild's team", guildID: "follows-team", command: "vb-next-game", want: "for Dig Deep"},
+		{name: "guild's team highlighted", guildID: "follows-team", command: "vb-ladder", want: "» Dig Deep"},
+		{name: "guild's team compared", guildID: "follows-team", command: "vb-h2h", want: "choose a team to compare against"},
+		{name: "guild without a subscription", guildID: "new", command: "vb-next-game", want: "for Aces"},
+		{name: "unsubscribed guild", guildID: "unsubscribed", command: "vb-next-game", want: "for Aces"},
+		{name: "guild without a team", guildID: "no-team", command: "vb-next-game", want: noTeam},
+		{name: "guild without a team history", guildID: "no-team", command: "vb-history", want: noTeam},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := registry.Handle(context.Background(), bot.Request{Command: tt.command, GuildID: tt.guildID})
+			if err != nil {
+				t.Fatalf("Registry.Handle() error = %v", err)
+			}
+
+			content := got.Content
+			for _, embed := range got.Embeds {
+				content += embed.Description
+				if embed.Footer != nil {
+					content += embed.Footer.Text
+				}
+			}
+
+			if !strings.Contains(content, tt.want) {
+				t.Errorf("Registry.Handle() = %q, want it to contain %q", content, tt.want)
+			}
+		})
+	}
+}
+
+func Test_commands_NoGames(t *testing.T) {
+	s := vqtest.NewServer(t)
+
+	var got []string
+	for _, command := range commands(commandEnv{vqClient: s.NewClient(), clients: testClients(s)}) {
+		got = append(got, command.Name)
+	}
+
+	want := []string{"vb-ladder", "vb-fairness", "vb-subscribe", "vb-unsubscribe", "vb-export"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("commands() = %v, want only the ladder commands %v", got, want)
+	}
+
+	// the fixtures export still needs the games, the command handler answers ErrNoGames.
+	config := s.ClientConfig()
+	config.GamesPath, config.GamesPageID = "", ""
+	if _, err := exportReply(context.Background(), vq.NewClient(config), "csv", "fixtures"); !errors.Is(err, vq.ErrNoGames) {
+		t.Errorf("exportReply() error = %v, want %v", err, vq.ErrNoGames)
+	}
+}
+
+func Test_loop(t *testing.T) {
+	watchTicker := time.NewTicker(20 * time.Millisecond)
+	defer watchTicker.Stop()
+
+	// reminders are checked far more often than the watchers run.
+	remindTicker := time.NewTicker(time.Millisecond)
+	defer remindTicker.Stop()
+
+	stop := make(chan os.Signal)
+	var watched, reminded int
+	l := loop{
+		watch:           watchTicker.C,
+		remind:          remindTicker.C,
+		checkForChanges: func() { watched++ },
+		postOdds:        func() { t.Errorf("loop.run() posted the odds without an odds tick") },
+		checkReminders:  func() { reminded++ },
+	}
+
+	done := make(chan struct{})
+	go func() {
+		l.run(stop)
+		close(done)
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+	stop <- os.Interrupt
+	<-done
+
+	if watched == 0 || reminded == 0 {
+		t.Errorf("loop.run() watched %d times and reminded %d times, want both to run", watched, reminded)
+	}
+}
+
+func Test_topicJobs(t *testing.T) {
+	md := subscription.Topic{Competition: "vqtest", Division: "MD"}
+	aces := subscription.Topic{Competition: "vqtest", Division: "MD", Team: "Aces"}
+
+	var created, ran []string
+	jobs := newTopicJobs(func(topic subscription.Topic) func() {
+		created = append(created, topic.String())
+		return func() { ran = append(ran, topic.String()) }
+	})
+
+	jobs.run([]subscription.Topic{md, aces})
+	jobs.run([]subscription.Topic{md})
+	// a topic followed again after it was dropped gets a new job.
+	jobs.run([]subscription.Topic{md, aces})
+
+	if want := []string{"vqtest/MD", "vqtest/MD/Aces", "vqtest/MD/Aces"}; !reflect.DeepEqual(created, want) {
+		t.Errorf("topicJobs.run() created %v, want %v", created, want)
+	}
+
+	if want := []string{"vqtest/MD", "vqtest/MD/Aces", "vqtest/MD", "vqtest/MD", "vqtest/MD/Aces"}; !reflect.DeepEqual(ran, want) {
+		t.Errorf("topicJobs.run() ran %v, want %v", ran, want)
+	}
+}
+
+func Test_archiveLadder(t *testing.T) {
+	s := vqtest.NewServer(t)
+	vqClient := s.NewClient()
+	archive := history.NewArchive(store.NewMemory(), "history")
+
+	ladder, err := vqClient.GetLadder(context.Background())
+	if err != nil {
+		t.Fatalf("GetLadder() error = %v", err)
+	}
+
+	archiveLadder(context.Background(), vqClient, archive, ladder)
+	// watchers of other divisions don't archive.
+	archiveLadder(context.Background(), vqClient, nil, ladder)
+
+	snapshots, err := archive.Snapshots()
+	if err != nil {
+		t.Fatalf("Archive.Snapshots() error = %v", err)
+	}
+
+	// rounds 1 to 3 of the fixture have results.
+	if len(snapshots) != 1 || snapshots[0].Round != "3" {
+		t.Errorf("Archive.Snapshots() = %+v, want one snapshot after round 3", snapshots)
+	}
+}
@@ -6,25 +6,42 @@ import (
 	"log/slog"
 	"net/http"
 	"sync"
+
+	"github.com/brewinski/home-lab-server/src/metro-volleyball-bot/store"
 )
 
 type DataSourceMonitor struct {
 	client       *http.Client
 	prevResponse string
 	mu           sync.Mutex
+	store        store.Store
+	storeKey     string
 }
 
 type Config struct {
 	Client   *http.Client
 	InitData string
+	// Store is optional. When set the last response is loaded from StoreKey on startup and
+	// saved whenever it changes, so a restart doesn't lose the baseline.
+	Store    store.Store
+	StoreKey string
 }
 
 func New(config Config) *DataSourceMonitor {
-	return &DataSourceMonitor{
-		config.Client,
-		config.InitData,
-		sync.Mutex{},
+	w := &DataSourceMonitor{
+		client:       config.Client,
+		prevResponse: config.InitData,
+		store:        config.Store,
+		storeKey:     config.StoreKey,
+	}
+
+	if w.store != nil && w.prevResponse == "" {
+		if _, err := store.GetJSON(w.store, w.storeKey, &w.prevResponse); err != nil {
+			slog.Error("unable to load monitor state", "error", err, "key", w.storeKey)
+		}
 	}
+
+	return w
 }
 
 func (w *DataSourceMonitor) CheckForChanges(url string) (bool, string, error) {
@@ -40,6 +57,7 @@ func (w *DataSourceMonitor) CheckForChanges(url string) (bool, string, error) {
 
 	if w.prevResponse == "" {
 		w.prevResponse = response
+		w.save()
 		return false, w.prevResponse, nil
 	}
 
@@ -48,9 +66,21 @@ func (w *DataSourceMonitor) CheckForChanges(url string) (bool, string, error) {
 	}
 
 	w.prevResponse = response
+	w.save()
 	return true, w.prevResponse, nil
 }
 
+// save persists the last response, failures are logged so monitoring carries on.
+func (w *DataSourceMonitor) save() {
+	if w.store == nil {
+		return
+	}
+
+	if err := store.PutJSON(w.store, w.storeKey, w.prevResponse); err != nil {
+		slog.Error("unable to save monitor state", "error", err, "key", w.storeKey)
+	}
+}
+
 // Monitor will monitor a specific page for changes.
 func (w *DataSourceMonitor) Monitor(pageUrl string) (string, error) {
 	response, err := w.client.Get(pageUrl)
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/brewinski/home-lab-server/src/metro-volleyball-bot/monitor"
+	"github.com/brewinski/home-lab-server/src/metro-volleyball-bot/store"
 )
 
 func TestDS_MonitorPage(t *testing.T) {
@@ -193,3 +194,29 @@ func TestDS_CheckPageForChangesRace(t *testing.T) {
 
 	wg.Wait()
 }
+
+func TestDS_CheckPageForChangesStore(t *testing.T) {
+	t.Parallel()
+
+	response := "first"
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(response))
+	}))
+	defer testServer.Close()
+
+	s := store.NewMemory()
+
+	w := monitor.New(monitor.Config{Client: &http.Client{}, Store: s, StoreKey: "draw"})
+	if changed, _, err := w.CheckForChanges(testServer.URL); changed || err != nil {
+		t.Fatalf("Web.CheckPageForChanges() = %v, %v, want the baseline set", changed, err)
+	}
+
+	// a restarted monitor picks up the saved baseline instead of starting over.
+	response = "second"
+	restarted := monitor.New(monitor.Config{Client: &http.Client{}, Store: s, StoreKey: "draw"})
+
+	changed, prev, err := restarted.CheckForChanges(testServer.URL)
+	if !changed || prev != "second" || err != nil {
+		t.Errorf("Web.CheckPageForChanges() = %v, %v, %v, want a change from the saved baseline", changed, prev, err)
+	}
+}
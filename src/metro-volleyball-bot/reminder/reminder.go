@@ -0,0 +1,278 @@
+// Package reminder posts reminders before a team's games and duties. Sent reminders are
+// kept in a store so they aren't posted again after a restart.
+package reminder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/brewinski/home-lab-server/src/metro-volleyball-bot/store"
+	"github.com/brewinski/home-lab-server/src/metro-volleyball-bot/vq"
+)
+
+// DefaultOffsets are used when Config.Offsets is empty.
+var DefaultOffsets = []time.Duration{24 * time.Hour, 2 * time.Hour}
+
+// sent reminders are forgotten this long after their game starts.
+const keepSent = 7 * 24 * time.Hour
+
+// Clock tells the scheduler the time, tests use a fixed clock.
+type Clock interface {
+	Now() time.Time
+}
+
+// ClockFunc adapts a function to a Clock.
+type ClockFunc func() time.Time
+
+func (f ClockFunc) Now() time.Time {
+	return f()
+}
+
+// SystemClock is the wall clock.
+var SystemClock Clock = ClockFunc(time.Now)
+
+// Source looks up a team's games, vq.Client is a Source.
+type Source interface {
+	AllGamesByTeam(ctx context.Context, team string) ([]vq.GameRecord, error)
+	AllGamesByTeamAndDuty(ctx context.Context, team string) ([]vq.GameRecord, error)
+}
+
+// Kind is whether the team is playing or on duty.
+type Kind string
+
+const (
+	KindGame Kind = "game"
+	KindDuty Kind = "duty"
+)
+
+// Reminder is a game or duty coming up for the team.
+type Reminder struct {
+	Team  string
+	Kind  Kind
+	Game  vq.GameRecord
+	Start time.Time
+	// Offset is how long before the start the reminder is for.
+	Offset time.Duration
+}
+
+// Due is when the reminder should be posted.
+func (r Reminder) Due() time.Time {
+	return r.Start.Add(-r.Offset)
+}
+
+// key identifies the reminder. The start is part of the key so a game that's moved gets
+// reminders for its new time.
+func (r Reminder) key() string {
+	game := r.Game.Fields.MatchID
+	if game == "" {
+		game = r.Game.ID
+	}
+
+	return fmt.Sprintf("%s|%s|%s|%d|%s", strings.ToLower(r.Team), r.Kind, game, r.Start.Unix(), r.Offset)
+}
+
+// String formats the reminder as a discord message.
+func (r Reminder) String() string {
+	sb := strings.Builder{}
+	f := r.Game.Fields
+
+	switch r.Kind {
+	case KindDuty:
+		sb.WriteString(fmt.Sprintf("Duty reminder for %s: <t:%d:R>\n", r.Team, r.Start.Unix()))
+		sb.WriteString(fmt.Sprintf("\tgame: %s v %s\n", f.TeamA, f.TeamB))
+	default:
+		sb.WriteString(fmt.Sprintf("Game reminder for %s: <t:%d:R>\n", r.Team, r.Start.Unix()))
+		sb.WriteString(fmt.Sprintf("\topponent: %s\n", r.Game.Opponent(r.Team)))
+	}
+
+	sb.WriteString(fmt.Sprintf("\tvenue: %s\n", f.Venue))
+	sb.WriteString(fmt.Sprintf("\tcourt: %s\n", f.Court))
+	sb.WriteString(fmt.Sprintf("\tround: %s\n", f.Round))
+	sb.WriteString(fmt.Sprintf("\ttime: <t:%d:F>\n", r.Start.Unix()))
+
+	return sb.String()
+}
+
+// Config configures a Scheduler.
+type Config struct {
+	Source Source
+	Team   string
+	// Offsets are how long before each game reminders are posted, defaults to
+	// DefaultOffsets.
+	Offsets []time.Duration
+	// Store keeps the sent reminders under Key.
+	Store store.Store
+	Key   string
+	// Clock defaults to SystemClock.
+	Clock Clock
+}
+
+// Scheduler finds the team's reminders that are due. It's safe for concurrent use.
+type Scheduler struct {
+	config Config
+	mu     sync.Mutex
+}
+
+// New returns a scheduler for the team.
+func New(config Config) *Scheduler {
+	if len(config.Offsets) == 0 {
+		config.Offsets = DefaultOffsets
+	}
+
+	if config.Clock == nil {
+		config.Clock = SystemClock
+	}
+
+	return &Scheduler{config: config}
+}
+
+// Check sends every reminder that's due and hasn't been sent. A reminder is due from its
+// offset before the game until the game starts. When several of a game's reminders are due
+// at once, e.g. after the bot was down, only the closest to the start is sent. Reminders
+// are saved as sent as soon as send succeeds, so a failed reminder is retried on the next
+// check and a restart doesn't post one twice. Nothing more is sent once a save fails. The
+// sent reminders are returned.
+func (s *Scheduler) Check(ctx context.Context, send func(Reminder) error) ([]Reminder, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.config.Clock.Now()
+
+	upcoming, err := s.upcoming(ctx, now)
+	if err != nil {
+		return nil, err
+	}
+
+	sent := map[string]time.Time{}
+	if _, err := store.GetJSON(s.config.Store, s.config.Key, &sent); err != nil {
+		return nil, fmt.Errorf("Scheduler.Check() unable to load sent reminders, got: %w", err)
+	}
+
+	// forget reminders for games that are long over.
+	forgotten := false
+	for key, start := range sent {
+		if now.Sub(start) > keepSent {
+			delete(sent, key)
+			forgotten = true
+		}
+	}
+
+	if forgotten {
+		if err := store.PutJSON(s.config.Store, s.config.Key, sent); err != nil {
+			return nil, fmt.Errorf("Scheduler.Check() unable to save sent reminders, got: %w", err)
+		}
+	}
+
+	var posted []Reminder
+	var errs []error
+	for _, due := range dueReminders(upcoming, s.config.Offsets, now) {
+		if _, ok := sent[due[0].key()]; ok {
+			continue
+		}
+
+		if err := send(due[0]); err != nil {
+			errs = append(errs, fmt.Errorf("Scheduler.Check() unable to send reminder, got: %w", err))
+			continue
+		}
+
+		// the earlier reminders for the game are stale, don't send them later.
+		for _, reminder := range due {
+			sent[reminder.key()] = reminder.Start
+		}
+		posted = append(posted, due[0])
+
+		// save before the next send, a reminder that can't be recorded would be sent again.
+		if err := store.PutJSON(s.config.Store, s.config.Key, sent); err != nil {
+			errs = append(errs, fmt.Errorf("Scheduler.Check() unable to save sent reminders, got: %w", err))
+			break
+		}
+	}
+
+	return posted, errors.Join(errs...)
+}
+
+// upcoming returns the team's games and duties that haven't started.
+func (s *Scheduler) upcoming(ctx context.Context, now time.Time) ([]Reminder, error) {
+	games, err := s.config.Source.AllGamesByTeam(ctx, s.config.Team)
+	if err != nil {
+		return nil, fmt.Errorf("Scheduler.Check() unable to get games, got: %w", err)
+	}
+
+	duties, err := s.config.Source.AllGamesByTeamAndDuty(ctx, s.config.Team)
+	if err != nil {
+		return nil, fmt.Errorf("Scheduler.Check() unable to get duties, got: %w", err)
+	}
+
+	var upcoming []Reminder
+	add := func(games []vq.GameRecord, kind Kind) {
+		for _, game := range games {
+			start, err := game.ParseGameDayTime()
+			if err != nil || !start.After(now) {
+				continue
+			}
+
+			upcoming = append(upcoming, Reminder{Team: s.config.Team, Kind: kind, Game: game, Start: start})
+		}
+	}
+	add(games, KindGame)
+	add(duties, KindDuty)
+
+	return upcoming, nil
+}
+
+// dueReminders returns the due reminders of each game, closest to the start first. Games
+// are ordered by start.
+func dueReminders(upcoming []Reminder, offsets []time.Duration, now time.Time) [][]Reminder {
+	sorted := append([]time.Duration(nil), offsets...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var due [][]Reminder
+	for _, game := range upcoming {
+		var reminders []Reminder
+		for _, offset := range sorted {
+			reminder := game
+			reminder.Offset = offset
+			if !now.Before(reminder.Due()) {
+				reminders = append(reminders, reminder)
+			}
+		}
+
+		if len(reminders) > 0 {
+			due = append(due, reminders)
+		}
+	}
+
+	sort.SliceStable(due, func(i, j int) bool { return due[i][0].Start.Before(due[j][0].Start) })
+
+	return due
+}
+
+// ParseOffsets parses a comma separated list of durations, e.g. "24h,2h". An empty list
+// disables reminders.
+func ParseOffsets(value string) ([]time.Duration, error) {
+	var offsets []time.Duration
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		offset, err := time.ParseDuration(part)
+		if err != nil {
+			return nil, fmt.Errorf("ParseOffsets() invalid offset %q, got: %w", part, err)
+		}
+
+		if offset <= 0 {
+			return nil, fmt.Errorf("ParseOffsets() offset %q must be positive", part)
+		}
+
+		offsets = append(offsets, offset)
+	}
+
+	return offsets, nil
+}
@@ -0,0 +1,273 @@
+package reminder_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brewinski/home-lab-server/src/metro-volleyball-bot/reminder"
+	"github.com/brewinski/home-lab-server/src/metro-volleyball-bot/store"
+	"github.com/brewinski/home-lab-server/src/metro-volleyball-bot/vq"
+)
+
+type fakeSource struct {
+	games  []vq.GameRecord
+	duties []vq.GameRecord
+}
+
+func (f *fakeSource) AllGamesByTeam(ctx context.Context, team string) ([]vq.GameRecord, error) {
+	return f.games, nil
+}
+
+func (f *fakeSource) AllGamesByTeamAndDuty(ctx context.Context, team string) ([]vq.GameRecord, error) {
+	return f.duties, nil
+}
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+var (
+	game = vq.GameRecord{ID: "g1", Fields: vq.GameFields{MatchID: "MD-101", Round: "3", GameDay: "19/2/2024", GameTime: "7:45pm", Venue: "Auchenflower Stadium", Court: "Court 1", TeamA: "Aces", TeamB: "APG", DutyTeam: "Setters"}}
+	duty = vq.GameRecord{ID: "g2", Fields: vq.GameFields{MatchID: "MD-102", Round: "3", GameDay: "19/2/2024", GameTime: "9:00pm", Venue: "Auchenflower Stadium", Court: "Court 2", TeamA: "Blockers", TeamB: "Dig Deep", DutyTeam: "Aces"}}
+)
+
+func start(t *testing.T, g vq.GameRecord) time.Time {
+	t.Helper()
+
+	start, err := g.ParseGameDayTime()
+	if err != nil {
+		t.Fatalf("ParseGameDayTime() error = %v", err)
+	}
+
+	return start
+}
+
+// sent returns the kind and offset of each reminder.
+func sent(reminders []reminder.Reminder) []string {
+	var got []string
+	for _, r := range reminders {
+		got = append(got, string(r.Kind)+" "+r.Offset.String())
+	}
+
+	return got
+}
+
+func TestScheduler_Check(t *testing.T) {
+	gameStart := start(t, game)
+
+	tests := []struct {
+		name string
+		// now is relative to the game's start, each check happens in order. The duty
+		// starts an hour and a quarter after the game.
+		checks []time.Duration
+		want   [][]string
+	}{
+		{
+			name:   "nothing due",
+			checks: []time.Duration{-48 * time.Hour},
+			want:   [][]string{nil},
+		},
+		{
+			name:   "each offset once",
+			checks: []time.Duration{-24 * time.Hour, -23 * time.Hour, -22 * time.Hour, -2 * time.Hour, -30 * time.Minute},
+			want:   [][]string{{"game 24h0m0s"}, nil, {"duty 24h0m0s"}, {"game 2h0m0s"}, {"duty 2h0m0s"}},
+		},
+		{
+			name:   "only the closest of the overdue offsets",
+			checks: []time.Duration{-30 * time.Minute, -10 * time.Minute},
+			want:   [][]string{{"game 2h0m0s", "duty 2h0m0s"}, nil},
+		},
+		{
+			name:   "not after the game starts",
+			checks: []time.Duration{time.Minute},
+			want:   [][]string{{"duty 2h0m0s"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := &fakeClock{}
+			s := store.NewMemory()
+			source := &fakeSource{games: []vq.GameRecord{game}, duties: []vq.GameRecord{duty}}
+
+			for i, check := range tt.checks {
+				clock.now = gameStart.Add(check)
+
+				// a new scheduler each check, like after a restart.
+				scheduler := reminder.New(reminder.Config{Source: source, Team: "Aces", Store: s, Key: "reminders", Clock: clock})
+				got, err := scheduler.Check(context.Background(), func(reminder.Reminder) error { return nil })
+				if err != nil {
+					t.Fatalf("Scheduler.Check() error = %v", err)
+				}
+
+				if !reflect.DeepEqual(sent(got), tt.want[i]) {
+					t.Errorf("Scheduler.Check() at %v = %v, want %v", check, sent(got), tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestScheduler_CheckRetriesFailedSends(t *testing.T) {
+	clock := &fakeClock{now: start(t, game).Add(-time.Hour)}
+	scheduler := reminder.New(reminder.Config{
+		Source: &fakeSource{games: []vq.GameRecord{game}},
+		Team:   "Aces",
+		Store:  store.NewMemory(),
+		Key:    "reminders",
+		Clock:  clock,
+	})
+
+	failed := errors.New("discord is down")
+	got, err := scheduler.Check(context.Background(), func(reminder.Reminder) error { return failed })
+	if !errors.Is(err, failed) || len(got) != 0 {
+		t.Fatalf("Scheduler.Check() = %v, %v, want the send error", got, err)
+	}
+
+	got, err = scheduler.Check(context.Background(), func(reminder.Reminder) error { return nil })
+	if err != nil || len(got) != 1 {
+		t.Errorf("Scheduler.Check() = %v, %v, want the reminder retried", got, err)
+	}
+}
+
+// failingStore fails every write.
+type failingStore struct {
+	store.Store
+	err error
+}
+
+func (f failingStore) Put(key string, value []byte) error {
+	return f.err
+}
+
+func TestScheduler_CheckSavesEachSend(t *testing.T) {
+	// the game and duty reminders are both due.
+	now := start(t, game).Add(-30 * time.Minute)
+	source := &fakeSource{games: []vq.GameRecord{game}, duties: []vq.GameRecord{duty}}
+	s := store.NewMemory()
+
+	// the bot stops after the first reminder is posted.
+	func() {
+		defer func() { recover() }()
+
+		scheduler := reminder.New(reminder.Config{Source: source, Team: "Aces", Store: s, Key: "reminders", Clock: &fakeClock{now: now}})
+		var calls int
+		scheduler.Check(context.Background(), func(reminder.Reminder) error {
+			if calls++; calls > 1 {
+				panic("stopped")
+			}
+			return nil
+		})
+	}()
+
+	scheduler := reminder.New(reminder.Config{Source: source, Team: "Aces", Store: s, Key: "reminders", Clock: &fakeClock{now: now}})
+	got, err := scheduler.Check(context.Background(), func(reminder.Reminder) error { return nil })
+	if want := []string{"duty 2h0m0s"}; err != nil || !reflect.DeepEqual(sent(got), want) {
+		t.Errorf("Scheduler.Check() after a restart = %v, %v, want %v", sent(got), err, want)
+	}
+}
+
+func TestScheduler_CheckStopsWhenSaveFails(t *testing.T) {
+	failed := errors.New("disk full")
+	scheduler := reminder.New(reminder.Config{
+		Source: &fakeSource{games: []vq.GameRecord{game}, duties: []vq.GameRecord{duty}},
+		Team:   "Aces",
+		Store:  failingStore{Store: store.NewMemory(), err: failed},
+		Key:    "reminders",
+		Clock:  &fakeClock{now: start(t, game).Add(-30 * time.Minute)},
+	})
+
+	var calls int
+	got, err := scheduler.Check(context.Background(), func(reminder.Reminder) error {
+		calls++
+		return nil
+	})
+	if !errors.Is(err, failed) || calls != 1 || len(got) != 1 {
+		t.Errorf("Scheduler.Check() = %v, %v after %d sends, want the save error after the first send", got, err, calls)
+	}
+}
+
+func TestScheduler_CheckRescheduledGame(t *testing.T) {
+	clock := &fakeClock{now: start(t, game).Add(-time.Hour)}
+	source := &fakeSource{games: []vq.GameRecord{game}}
+	scheduler := reminder.New(reminder.Config{Source: source, Team: "Aces", Store: store.NewMemory(), Key: "reminders", Clock: clock})
+	send := func(reminder.Reminder) error { return nil }
+
+	if got, _ := scheduler.Check(context.Background(), send); len(got) != 1 {
+		t.Fatalf("Scheduler.Check() = %v, want one reminder", got)
+	}
+
+	moved := game
+	moved.Fields.GameTime = "8:15pm"
+	source.games = []vq.GameRecord{moved}
+
+	got, err := scheduler.Check(context.Background(), send)
+	if err != nil || len(got) != 1 || got[0].Start != start(t, moved) {
+		t.Errorf("Scheduler.Check() = %v, %v, want a reminder for the new time", got, err)
+	}
+}
+
+func TestReminder_String(t *testing.T) {
+	gameStart := start(t, game)
+
+	tests := []struct {
+		name     string
+		reminder reminder.Reminder
+		want     []string
+	}{
+		{
+			name:     "game",
+			reminder: reminder.Reminder{Team: "Aces", Kind: reminder.KindGame, Game: game, Start: gameStart, Offset: 2 * time.Hour},
+			want:     []string{"Game reminder for Aces", "opponent: APG", "venue: Auchenflower Stadium", "court: Court 1"},
+		},
+		{
+			name:     "duty",
+			reminder: reminder.Reminder{Team: "Aces", Kind: reminder.KindDuty, Game: duty, Start: start(t, duty), Offset: 2 * time.Hour},
+			want:     []string{"Duty reminder for Aces", "game: Blockers v Dig Deep", "court: Court 2"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.reminder.String()
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("Reminder.String() = %q, want it to contain %q", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestParseOffsets(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    []time.Duration
+		wantErr bool
+	}{
+		{name: "defaults", value: "24h,2h", want: []time.Duration{24 * time.Hour, 2 * time.Hour}},
+		{name: "spaces", value: " 30m , 1h ", want: []time.Duration{30 * time.Minute, time.Hour}},
+		{name: "empty disables", value: "", want: nil},
+		{name: "invalid", value: "soon", wantErr: true},
+		{name: "negative", value: "-1h", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := reminder.ParseOffsets(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseOffsets() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseOffsets() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
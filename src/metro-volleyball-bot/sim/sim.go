@@ -0,0 +1,282 @@
+// Package sim estimates where each team will finish by playing out the remaining fixtures
+// many times.
+package sim
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/brewinski/home-lab-server/src/metro-volleyball-bot/vq"
+)
+
+// Defaults used when Options fields are left as zero.
+const (
+	DefaultIterations   = 10000
+	DefaultSetsPerMatch = 3
+	DefaultFinalsSpots  = 4
+)
+
+// Options configure a simulation.
+type Options struct {
+	// Iterations is the number of seasons simulated.
+	Iterations int
+	// SetsPerMatch is the number of sets played in every match, all of them are played
+	// even once the match is decided.
+	SetsPerMatch int
+	// FinalsSpots is the number of teams that make finals.
+	FinalsSpots int
+	// Rand is the source of randomness, seeded from the clock when nil.
+	Rand *rand.Rand
+}
+
+// Odds is a team's estimated chances of finishing in each position.
+type Odds struct {
+	Team        string
+	CurrentRank int
+	// Positions is the chance of finishing in each position, index 0 is 1st.
+	Positions []float64
+	// Finals is the chance of finishing inside the finals spots.
+	Finals float64
+	// ExpectedPoints is the average competition points at the end of the season.
+	ExpectedPoints float64
+}
+
+// Result is the outcome of a simulation.
+type Result struct {
+	Odds []Odds
+	// Remaining is the number of unplayed fixtures that were simulated.
+	Remaining   int
+	Iterations  int
+	FinalsSpots int
+}
+
+type team struct {
+	standing vq.Standing
+	// strength is the chance the team wins a set against an average team, taken from the
+	// sets won and lost so far.
+	strength float64
+}
+
+type fixture struct {
+	a, b int
+}
+
+// Simulate plays out the unplayed games many times on top of the current standings.
+// Games that start after now are treated as unplayed. Games involving teams that aren't
+// on the ladder, or without a valid start, are ignored.
+//
+// Each set is won with a chance based on both teams' set records so far, and every team
+// gets the scheme's points for the match result plus the set points for each set won.
+// Ties on points are broken by set ratio then point ratio.
+func Simulate(standings []vq.Standing, games []vq.GameRecord, scheme vq.PointsScheme, now time.Time, opts Options) (Result, error) {
+	if len(standings) == 0 {
+		return Result{}, errors.New("Simulate() no standings to simulate")
+	}
+
+	opts = withDefaults(opts)
+
+	teams := make([]team, len(standings))
+	index := make(map[string]int, len(standings))
+	for i, standing := range standings {
+		teams[i] = team{
+			standing: standing,
+			// add one set each way so teams that haven't played aren't certain winners or
+			// losers.
+			strength: float64(standing.Sets.For+1) / float64(standing.Sets.For+standing.Sets.Against+2),
+		}
+		index[teamKey(standing.Team)] = i
+	}
+
+	var fixtures []fixture
+	for _, game := range games {
+		start, err := game.ParseGameDayTime()
+		if err != nil || !start.After(now) {
+			continue
+		}
+
+		a, okA := index[teamKey(game.Fields.TeamA)]
+		b, okB := index[teamKey(game.Fields.TeamB)]
+		if !okA || !okB {
+			continue
+		}
+
+		fixtures = append(fixtures, fixture{a, b})
+	}
+
+	positions := make([][]int, len(teams))
+	for i := range positions {
+		positions[i] = make([]int, len(teams))
+	}
+	totalPoints := make([]float64, len(teams))
+
+	season := make([]simulated, len(teams))
+	order := make([]int, len(teams))
+
+	for iteration := 0; iteration < opts.Iterations; iteration++ {
+		for i, t := range teams {
+			season[i] = simulated{
+				points:       t.standing.CompetitionPoints,
+				sets:         t.standing.Sets,
+				pointsRecord: t.standing.Points,
+				tiebreak:     opts.Rand.Float64(),
+			}
+		}
+
+		for _, f := range fixtures {
+			playMatch(&season[f.a], &season[f.b], teams[f.a].strength, teams[f.b].strength, scheme, opts)
+		}
+
+		for i := range order {
+			order[i] = i
+		}
+		sort.Slice(order, func(i, j int) bool {
+			return season[order[i]].ahead(season[order[j]])
+		})
+
+		for position, i := range order {
+			positions[i][position]++
+			totalPoints[i] += season[i].points
+		}
+	}
+
+	result := Result{
+		Odds:        make([]Odds, len(teams)),
+		Remaining:   len(fixtures),
+		Iterations:  opts.Iterations,
+		FinalsSpots: opts.FinalsSpots,
+	}
+
+	for i, t := range teams {
+		odds := Odds{
+			Team:           t.standing.Team,
+			CurrentRank:    t.standing.Rank,
+			Positions:      make([]float64, len(teams)),
+			ExpectedPoints: totalPoints[i] / float64(opts.Iterations),
+		}
+
+		for position, count := range positions[i] {
+			odds.Positions[position] = float64(count) / float64(opts.Iterations)
+			if position < opts.FinalsSpots {
+				odds.Finals += odds.Positions[position]
+			}
+		}
+
+		result.Odds[i] = odds
+	}
+
+	return result, nil
+}
+
+// simulated is a team's running totals during one simulated season.
+type simulated struct {
+	points       float64
+	sets         vq.Tally
+	pointsRecord vq.Tally
+	tiebreak     float64
+}
+
+// ahead reports whether s finishes above other on the ladder.
+func (s simulated) ahead(other simulated) bool {
+	if s.points != other.points {
+		return s.points > other.points
+	}
+
+	if ratio, otherRatio := s.sets.Ratio(), other.sets.Ratio(); ratio != otherRatio {
+		return ratio > otherRatio
+	}
+
+	if ratio, otherRatio := s.pointsRecord.Ratio(), other.pointsRecord.Ratio(); ratio != otherRatio {
+		return ratio > otherRatio
+	}
+
+	return s.tiebreak > other.tiebreak
+}
+
+func playMatch(a, b *simulated, strengthA, strengthB float64, scheme vq.PointsScheme, opts Options) {
+	// log5, the chance a beats b given how each fares against an average team.
+	p := strengthA * (1 - strengthB) / (strengthA*(1-strengthB) + strengthB*(1-strengthA))
+
+	setsA := 0
+	for set := 0; set < opts.SetsPerMatch; set++ {
+		if opts.Rand.Float64() < p {
+			setsA++
+		}
+	}
+	setsB := opts.SetsPerMatch - setsA
+
+	a.sets.For += setsA
+	a.sets.Against += setsB
+	b.sets.For += setsB
+	b.sets.Against += setsA
+
+	a.points += float64(setsA) * scheme.Set
+	b.points += float64(setsB) * scheme.Set
+
+	switch {
+	case setsA > setsB:
+		a.points += scheme.Win
+		b.points += scheme.Loss
+	case setsB > setsA:
+		b.points += scheme.Win
+		a.points += scheme.Loss
+	default:
+		a.points += scheme.Draw
+		b.points += scheme.Draw
+	}
+}
+
+func withDefaults(opts Options) Options {
+	if opts.Iterations <= 0 {
+		opts.Iterations = DefaultIterations
+	}
+
+	if opts.SetsPerMatch <= 0 {
+		opts.SetsPerMatch = DefaultSetsPerMatch
+	}
+
+	if opts.FinalsSpots <= 0 {
+		opts.FinalsSpots = DefaultFinalsSpots
+	}
+
+	if opts.Rand == nil {
+		opts.Rand = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+
+	return opts
+}
+
+func teamKey(team string) string {
+	return strings.ToLower(strings.TrimSpace(team))
+}
+
+// Report formats the result as a discord message, one line per team in ladder order.
+func Report(result Result) string {
+	sb := strings.Builder{}
+	sb.WriteString(fmt.Sprintf("Finals odds, top %d from %d simulated seasons with %d games left:\n```", result.FinalsSpots, result.Iterations, result.Remaining))
+
+	for _, odds := range result.Odds {
+		likeliest := 0
+		for position, chance := range odds.Positions {
+			if chance > odds.Positions[likeliest] {
+				likeliest = position
+			}
+		}
+
+		sb.WriteString(fmt.Sprintf("\n%2d. %-20s finals %5.1f%%  likely %s (%4.1f%%)  ~%.1f pts",
+			odds.CurrentRank,
+			odds.Team,
+			odds.Finals*100,
+			vq.Ordinal(likeliest+1),
+			odds.Positions[likeliest]*100,
+			odds.ExpectedPoints,
+		))
+	}
+
+	sb.WriteString("\n```")
+
+	return sb.String()
+}
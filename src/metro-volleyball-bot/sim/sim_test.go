@@ -0,0 +1,176 @@
+package sim_test
+
+import (
+	"math"
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brewinski/home-lab-server/src/metro-volleyball-bot/sim"
+	"github.com/brewinski/home-lab-server/src/metro-volleyball-bot/vq"
+	"github.com/brewinski/home-lab-server/src/metro-volleyball-bot/vq/vqtest"
+)
+
+var scheme = vq.PointsScheme{Win: 3, Draw: 1, Set: 1}
+
+func game(round, day, teamA, teamB string) vq.GameRecord {
+	return vq.GameRecord{
+		ID:     "rec" + round + teamA,
+		Fields: vq.GameFields{TeamA: teamA, TeamB: teamB, Round: round, GameDay: day, GameTime: "7:45pm"},
+	}
+}
+
+func TestSimulate(t *testing.T) {
+	standings := []vq.Standing{
+		{Team: "Aces", Rank: 1, CompetitionPoints: 12, Sets: vq.Tally{For: 9, Against: 0}},
+		{Team: "APG", Rank: 2, CompetitionPoints: 6, Sets: vq.Tally{For: 5, Against: 4}},
+		{Team: "Blockers", Rank: 3, CompetitionPoints: 5, Sets: vq.Tally{For: 4, Against: 5}},
+		{Team: "Dig Deep", Rank: 4, CompetitionPoints: 0, Sets: vq.Tally{For: 0, Against: 9}},
+	}
+	now := time.Date(2024, time.February, 13, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		games         []vq.GameRecord
+		finalsSpots   int
+		wantRemaining int
+		check         func(t *testing.T, result sim.Result)
+	}{
+		{
+			name: "finished season keeps the current order",
+			games: []vq.GameRecord{
+				game("1", "5/2/2024", "Aces", "Dig Deep"),
+			},
+			finalsSpots:   2,
+			wantRemaining: 0,
+			check: func(t *testing.T, result sim.Result) {
+				for i, odds := range result.Odds {
+					if odds.Positions[i] != 1 {
+						t.Errorf("Simulate() %s positions = %v, want certain to finish %d", odds.Team, odds.Positions, i+1)
+					}
+				}
+				if result.Odds[1].Finals != 1 || result.Odds[2].Finals != 0 {
+					t.Errorf("Simulate() finals = %v, %v, want the top two certain", result.Odds[1].Finals, result.Odds[2].Finals)
+				}
+			},
+		},
+		{
+			name: "remaining games are played out",
+			games: []vq.GameRecord{
+				game("3", "19/2/2024", "APG", "Blockers"),
+				game("3", "19/2/2024", "Aces", "Dig Deep"),
+				game("4", "26/2/2024", "Blockers", "Aces"),
+				game("4", "26/2/2024", "Dig Deep", "APG"),
+				// not on the ladder.
+				game("4", "26/2/2024", "Spike Force", "APG"),
+			},
+			finalsSpots:   2,
+			wantRemaining: 4,
+			check: func(t *testing.T, result sim.Result) {
+				aces, apg, blockers := result.Odds[0], result.Odds[1], result.Odds[2]
+				if aces.Finals < 0.99 {
+					t.Errorf("Simulate() Aces finals = %v, want near certain", aces.Finals)
+				}
+				if apg.Finals <= blockers.Finals || blockers.Finals == 0 {
+					t.Errorf("Simulate() APG finals = %v, Blockers finals = %v, want both possible with APG ahead", apg.Finals, blockers.Finals)
+				}
+				if aces.ExpectedPoints <= 12 {
+					t.Errorf("Simulate() Aces expected points = %v, want more than the current 12", aces.ExpectedPoints)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := sim.Simulate(standings, tt.games, scheme, now, sim.Options{
+				Iterations:  2000,
+				FinalsSpots: tt.finalsSpots,
+				Rand:        rand.New(rand.NewSource(1)),
+			})
+			if err != nil {
+				t.Fatalf("Simulate() error = %v", err)
+			}
+
+			if result.Remaining != tt.wantRemaining {
+				t.Errorf("Simulate() remaining = %v, want %v", result.Remaining, tt.wantRemaining)
+			}
+
+			checkProbabilities(t, result)
+			tt.check(t, result)
+		})
+	}
+}
+
+// checkProbabilities checks every team finishes somewhere and every position is filled.
+func checkProbabilities(t *testing.T, result sim.Result) {
+	t.Helper()
+
+	positionTotals := make([]float64, len(result.Odds))
+	for _, odds := range result.Odds {
+		total := 0.0
+		for position, chance := range odds.Positions {
+			total += chance
+			positionTotals[position] += chance
+		}
+		if math.Abs(total-1) > 1e-9 {
+			t.Errorf("Simulate() %s positions sum to %v, want 1", odds.Team, total)
+		}
+	}
+
+	for position, total := range positionTotals {
+		if math.Abs(total-1) > 1e-9 {
+			t.Errorf("Simulate() position %d sums to %v, want 1", position+1, total)
+		}
+	}
+}
+
+func TestSimulate_Fixtures(t *testing.T) {
+	var ladder vq.GetLadderResponseBody
+	for _, record := range vqtest.LadderFixture(t) {
+		if record.Fields.Division == vqtest.Division {
+			ladder.Records = append(ladder.Records, record)
+		}
+	}
+
+	standings, err := ladder.Standings()
+	if err != nil {
+		t.Fatalf("GetLadderResponseBody.Standings() error = %v", err)
+	}
+
+	scheme, err := ladder.PointsScheme()
+	if err != nil {
+		t.Fatalf("GetLadderResponseBody.PointsScheme() error = %v", err)
+	}
+
+	// the fixture ladder is after round 3.
+	now := time.Date(2024, time.February, 20, 0, 0, 0, 0, time.UTC)
+	result, err := sim.Simulate(standings, vqtest.GamesFixture(t), scheme, now, sim.Options{Iterations: 500, Rand: rand.New(rand.NewSource(1))})
+	if err != nil {
+		t.Fatalf("Simulate() error = %v", err)
+	}
+
+	if result.Remaining != 6 || len(result.Odds) != len(standings) {
+		t.Errorf("Simulate() remaining = %v, odds = %v, want 6 games for %d teams", result.Remaining, len(result.Odds), len(standings))
+	}
+
+	checkProbabilities(t, result)
+
+	report := sim.Report(result)
+	if !strings.Contains(report, "top 4 from 500 simulated seasons with 6 games left") || !strings.Contains(report, standings[0].Team) {
+		t.Errorf("Report() = %v, want a summary and a line per team", report)
+	}
+}
+
+func TestSimulate_Invalid(t *testing.T) {
+	if _, err := sim.Simulate(nil, nil, scheme, time.Now(), sim.Options{}); err == nil {
+		t.Errorf("Simulate() expected an error without standings")
+	}
+
+	standings := []vq.Standing{{Team: "Aces", Rank: 1}, {Team: "APG", Rank: 2}}
+	games := []vq.GameRecord{{ID: "bad", Fields: vq.GameFields{TeamA: "Aces", TeamB: "APG", GameDay: "someday"}}}
+	result, err := sim.Simulate(standings, games, scheme, time.Now(), sim.Options{Iterations: 10})
+	if err != nil || result.Remaining != 0 {
+		t.Errorf("Simulate() = %+v, %v, want games without a valid start skipped", result, err)
+	}
+}
@@ -0,0 +1,90 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var stateBucket = []byte("state")
+
+// Bolt is a Store backed by an embedded bbolt database. Every write is a transaction, so
+// values are replaced atomically.
+type Bolt struct {
+	db *bolt.DB
+}
+
+// NewBolt opens, or creates, the database at path.
+func NewBolt(path string) (*Bolt, error) {
+	if path == "" {
+		return nil, errors.New("NewBolt() a database path is required")
+	}
+
+	// bolt locks the file, don't hang forever if another instance has it open.
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("NewBolt() unable to open database, got: %w", err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(stateBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("NewBolt() unable to create bucket, got: %w", err)
+	}
+
+	return &Bolt{db: db}, nil
+}
+
+func (b *Bolt) Get(key string) ([]byte, error) {
+	var value []byte
+
+	err := b.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(stateBucket).Get([]byte(key))
+		if data == nil {
+			return ErrNotFound
+		}
+
+		// data is only valid for the life of the transaction.
+		value = append([]byte(nil), data...)
+		return nil
+	})
+	if errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Bolt.Get() unable to read %s, got: %w", key, err)
+	}
+
+	return value, nil
+}
+
+func (b *Bolt) Put(key string, value []byte) error {
+	err := b.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(stateBucket).Put([]byte(key), value)
+	})
+	if err != nil {
+		return fmt.Errorf("Bolt.Put() unable to write %s, got: %w", key, err)
+	}
+
+	return nil
+}
+
+func (b *Bolt) Delete(key string) error {
+	err := b.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(stateBucket).Delete([]byte(key))
+	})
+	if err != nil {
+		return fmt.Errorf("Bolt.Delete() unable to delete %s, got: %w", key, err)
+	}
+
+	return nil
+}
+
+func (b *Bolt) Close() error {
+	return b.db.Close()
+}
@@ -0,0 +1,101 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// File is a Store that keeps each key in its own json file in a directory. Writes go to
+// a temporary file that's renamed over the old value, so readers never see a partial
+// write.
+type File struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewFile returns a store writing to dir, creating it if it doesn't exist.
+func NewFile(dir string) (*File, error) {
+	if dir == "" {
+		return nil, errors.New("NewFile() a directory is required")
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("NewFile() unable to create directory, got: %w", err)
+	}
+
+	return &File{dir: dir}, nil
+}
+
+func (f *File) Get(key string) ([]byte, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	data, err := os.ReadFile(f.path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("File.Get() unable to read %s, got: %w", key, err)
+	}
+
+	return data, nil
+}
+
+func (f *File) Put(key string, value []byte) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	tmp, err := os.CreateTemp(f.dir, ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("File.Put() unable to create temp file, got: %w", err)
+	}
+	// a no-op once the rename succeeds.
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(value); err != nil {
+		tmp.Close()
+		return fmt.Errorf("File.Put() unable to write %s, got: %w", key, err)
+	}
+
+	// flush to disk before the rename so a crash can't leave an empty file behind.
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("File.Put() unable to sync %s, got: %w", key, err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("File.Put() unable to close %s, got: %w", key, err)
+	}
+
+	if err := os.Rename(tmp.Name(), f.path(key)); err != nil {
+		return fmt.Errorf("File.Put() unable to replace %s, got: %w", key, err)
+	}
+
+	return nil
+}
+
+func (f *File) Delete(key string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	err := os.Remove(f.path(key))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("File.Delete() unable to delete %s, got: %w", key, err)
+	}
+
+	return nil
+}
+
+func (f *File) Close() error {
+	return nil
+}
+
+// keys are escaped so they can't point outside the directory.
+func (f *File) path(key string) string {
+	return filepath.Join(f.dir, url.PathEscape(key)+".json")
+}
@@ -0,0 +1,76 @@
+// Package store persists the snapshots the watchers compare against, so the bot keeps its
+// baseline across restarts.
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrNotFound is returned by Get when nothing has been stored for the key.
+var ErrNotFound = errors.New("store: key not found")
+
+// Store is a key value store for watcher state. Implementations must be safe for
+// concurrent use and replace values atomically, a crash mid write leaves the previous
+// value in place.
+type Store interface {
+	Get(key string) ([]byte, error)
+	Put(key string, value []byte) error
+	Delete(key string) error
+	Close() error
+}
+
+// Backends accepted by Open.
+const (
+	BackendFile   = "file"
+	BackendBolt   = "bolt"
+	BackendMemory = "memory"
+)
+
+// Open opens a store using the named backend. path is the directory for the file backend
+// and the database file for the bolt backend, it's ignored for the memory backend.
+func Open(backend, path string) (Store, error) {
+	switch backend {
+	case BackendFile:
+		return NewFile(path)
+	case BackendBolt:
+		return NewBolt(path)
+	case BackendMemory:
+		return NewMemory(), nil
+	default:
+		return nil, fmt.Errorf("Open() unknown store backend %q", backend)
+	}
+}
+
+// GetJSON decodes the value stored for key into v. It returns false when nothing has
+// been stored for the key.
+func GetJSON(s Store, key string, v any) (bool, error) {
+	data, err := s.Get(key)
+	if errors.Is(err, ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("GetJSON() unable to read %s, got: %w", key, err)
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return false, fmt.Errorf("GetJSON() unable to decode %s, got: %w", key, err)
+	}
+
+	return true, nil
+}
+
+// PutJSON encodes v as json and stores it for key.
+func PutJSON(s Store, key string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("PutJSON() unable to encode %s, got: %w", key, err)
+	}
+
+	if err := s.Put(key, data); err != nil {
+		return fmt.Errorf("PutJSON() unable to write %s, got: %w", key, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,122 @@
+package store_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/brewinski/home-lab-server/src/metro-volleyball-bot/store"
+)
+
+func TestStore(t *testing.T) {
+	tests := []struct {
+		name string
+		open func(t *testing.T) store.Store
+	}{
+		{
+			name: "memory",
+			open: func(t *testing.T) store.Store { return store.NewMemory() },
+		},
+		{
+			name: "file",
+			open: func(t *testing.T) store.Store {
+				s, err := store.NewFile(filepath.Join(t.TempDir(), "state"))
+				if err != nil {
+					t.Fatalf("NewFile() error = %v", err)
+				}
+				return s
+			},
+		},
+		{
+			name: "bolt",
+			open: func(t *testing.T) store.Store {
+				s, err := store.NewBolt(filepath.Join(t.TempDir(), "state.db"))
+				if err != nil {
+					t.Fatalf("NewBolt() error = %v", err)
+				}
+				return s
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := tt.open(t)
+			defer s.Close()
+
+			if _, err := s.Get("ladder"); !errors.Is(err, store.ErrNotFound) {
+				t.Fatalf("Store.Get() error = %v, want ErrNotFound", err)
+			}
+
+			if err := s.Put("ladder", []byte("first")); err != nil {
+				t.Fatalf("Store.Put() error = %v", err)
+			}
+			if err := s.Put("ladder", []byte("second")); err != nil {
+				t.Fatalf("Store.Put() error = %v", err)
+			}
+
+			got, err := s.Get("ladder")
+			if err != nil || string(got) != "second" {
+				t.Fatalf("Store.Get() = %q, %v, want the last value written", got, err)
+			}
+
+			if err := s.Delete("ladder"); err != nil {
+				t.Fatalf("Store.Delete() error = %v", err)
+			}
+			if err := s.Delete("ladder"); err != nil {
+				t.Fatalf("Store.Delete() error = %v, want deleting a missing key to succeed", err)
+			}
+
+			if _, err := s.Get("ladder"); !errors.Is(err, store.ErrNotFound) {
+				t.Fatalf("Store.Get() error = %v, want ErrNotFound after delete", err)
+			}
+		})
+	}
+}
+
+func TestFile_Reopen(t *testing.T) {
+	dir := t.TempDir()
+
+	s, err := store.NewFile(dir)
+	if err != nil {
+		t.Fatalf("NewFile() error = %v", err)
+	}
+
+	if err := store.PutJSON(s, "games/aces", map[string]int{"round": 3}); err != nil {
+		t.Fatalf("PutJSON() error = %v", err)
+	}
+
+	// no temp files should be left behind and the key can't escape the directory.
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 || entries[0].Name() != "games%2Faces.json" {
+		t.Errorf("NewFile() wrote %v, want a single escaped file", entries)
+	}
+
+	reopened, err := store.NewFile(dir)
+	if err != nil {
+		t.Fatalf("NewFile() error = %v", err)
+	}
+
+	var got map[string]int
+	found, err := store.GetJSON(reopened, "games/aces", &got)
+	if err != nil || !found || got["round"] != 3 {
+		t.Errorf("GetJSON() = %v, %v, %v, want the value written before reopening", got, found, err)
+	}
+
+	found, err = store.GetJSON(reopened, "missing", &got)
+	if err != nil || found {
+		t.Errorf("GetJSON() = %v, %v, want not found", found, err)
+	}
+}
+
+func TestOpen(t *testing.T) {
+	if _, err := store.Open("postgres", t.TempDir()); err == nil {
+		t.Errorf("Open() expected an error for an unknown backend")
+	}
+
+	s, err := store.Open(store.BackendBolt, filepath.Join(t.TempDir(), "state.db"))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	s.Close()
+}
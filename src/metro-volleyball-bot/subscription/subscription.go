@@ -0,0 +1,225 @@
+// Package subscription keeps track of which competition, division and team each guild
+// follows and where its updates are sent.
+package subscription
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/brewinski/home-lab-server/src/metro-volleyball-bot/store"
+)
+
+// Subscription is what a guild follows and the channel that receives its updates.
+type Subscription struct {
+	GuildID string `json:"guild_id"`
+	// ChannelID is empty for the default subscription, its updates go to the channel
+	// named in the bot's config.
+	ChannelID   string `json:"channel_id"`
+	Competition string `json:"competition"`
+	Division    string `json:"division"`
+	// Team is optional, without one the guild only gets division wide updates.
+	Team string `json:"team,omitempty"`
+	// Disabled is set when the guild unsubscribes, so it doesn't fall back to the
+	// default subscription.
+	Disabled bool `json:"disabled,omitempty"`
+}
+
+// Topic describes an update. Ladder updates are for a whole division and leave Team
+// empty, fixture updates are for a single team.
+type Topic struct {
+	Competition string
+	Division    string
+	Team        string
+}
+
+// String formats the topic, e.g. "vqmetro24s1/MD/aces".
+func (t Topic) String() string {
+	if t.Team == "" {
+		return t.Competition + "/" + t.Division
+	}
+
+	return t.Competition + "/" + t.Division + "/" + t.Team
+}
+
+// Topic is the most specific topic the subscription receives.
+func (s Subscription) Topic() Topic {
+	return Topic{Competition: s.Competition, Division: s.Division, Team: s.Team}
+}
+
+// Matches reports whether the guild wants updates for the topic. Names are compared
+// ignoring case.
+func (s Subscription) Matches(t Topic) bool {
+	if s.Disabled {
+		return false
+	}
+
+	if !same(s.Competition, t.Competition) || !same(s.Division, t.Division) {
+		return false
+	}
+
+	return t.Team == "" || same(s.Team, t.Team)
+}
+
+// String formats the subscription as a discord message.
+func (s Subscription) String() string {
+	if s.Disabled {
+		return "not subscribed to any updates"
+	}
+
+	sb := strings.Builder{}
+	sb.WriteString(fmt.Sprintf("following %s division %s", s.Competition, s.Division))
+	if s.Team != "" {
+		sb.WriteString(fmt.Sprintf(" and team %s", s.Team))
+	}
+
+	if s.ChannelID != "" {
+		sb.WriteString(fmt.Sprintf(" in <#%s>", s.ChannelID))
+	}
+
+	return sb.String()
+}
+
+func same(a, b string) bool {
+	return strings.EqualFold(strings.TrimSpace(a), strings.TrimSpace(b))
+}
+
+// Topics returns the distinct topics the subscriptions receive, ladder topics for every
+// division followed and fixture topics for every team followed, in alphabetical order.
+func Topics(subscriptions []Subscription) []Topic {
+	seen := map[string]bool{}
+	var topics []Topic
+
+	add := func(t Topic) {
+		key := strings.ToLower(t.String())
+		if seen[key] {
+			return
+		}
+
+		seen[key] = true
+		topics = append(topics, t)
+	}
+
+	for _, s := range subscriptions {
+		if s.Disabled {
+			continue
+		}
+
+		add(Topic{Competition: s.Competition, Division: s.Division})
+		if s.Team != "" {
+			add(s.Topic())
+		}
+	}
+
+	sort.Slice(topics, func(i, j int) bool {
+		return strings.ToLower(topics[i].String()) < strings.ToLower(topics[j].String())
+	})
+
+	return topics
+}
+
+// Registry is the subscription of every guild, kept in a store under a single key. It's
+// safe for concurrent use.
+type Registry struct {
+	store store.Store
+	key   string
+	mu    sync.Mutex
+}
+
+// NewRegistry returns a registry kept in s under key.
+func NewRegistry(s store.Store, key string) *Registry {
+	return &Registry{store: s, key: key}
+}
+
+// Subscribe saves the guild's subscription, replacing any it already has.
+func (r *Registry) Subscribe(subscription Subscription) error {
+	if subscription.GuildID == "" {
+		return fmt.Errorf("Registry.Subscribe() the subscription has no guild")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	subscriptions, err := r.load()
+	if err != nil {
+		return err
+	}
+
+	subscriptions[subscription.GuildID] = subscription
+
+	return r.save(subscriptions)
+}
+
+// Unsubscribe stops the guild's updates. It returns false when the guild was already
+// unsubscribed.
+func (r *Registry) Unsubscribe(guildID string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	subscriptions, err := r.load()
+	if err != nil {
+		return false, err
+	}
+
+	if current, ok := subscriptions[guildID]; ok && current.Disabled {
+		return false, nil
+	}
+
+	subscriptions[guildID] = Subscription{GuildID: guildID, Disabled: true}
+
+	return true, r.save(subscriptions)
+}
+
+// Get returns the guild's subscription. The bool is false when the guild has never
+// subscribed or unsubscribed.
+func (r *Registry) Get(guildID string) (Subscription, bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	subscriptions, err := r.load()
+	if err != nil {
+		return Subscription{}, false, err
+	}
+
+	subscription, ok := subscriptions[guildID]
+
+	return subscription, ok, nil
+}
+
+// All returns every saved subscription ordered by guild.
+func (r *Registry) All() ([]Subscription, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	subscriptions, err := r.load()
+	if err != nil {
+		return nil, err
+	}
+
+	all := make([]Subscription, 0, len(subscriptions))
+	for _, subscription := range subscriptions {
+		all = append(all, subscription)
+	}
+
+	sort.Slice(all, func(i, j int) bool { return all[i].GuildID < all[j].GuildID })
+
+	return all, nil
+}
+
+func (r *Registry) load() (map[string]Subscription, error) {
+	subscriptions := map[string]Subscription{}
+	if _, err := store.GetJSON(r.store, r.key, &subscriptions); err != nil {
+		return nil, fmt.Errorf("Registry.load() unable to load subscriptions, got: %w", err)
+	}
+
+	return subscriptions, nil
+}
+
+func (r *Registry) save(subscriptions map[string]Subscription) error {
+	if err := store.PutJSON(r.store, r.key, subscriptions); err != nil {
+		return fmt.Errorf("Registry.save() unable to save subscriptions, got: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,104 @@
+package subscription_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/brewinski/home-lab-server/src/metro-volleyball-bot/store"
+	"github.com/brewinski/home-lab-server/src/metro-volleyball-bot/subscription"
+)
+
+func TestSubscription_Matches(t *testing.T) {
+	division := subscription.Subscription{GuildID: "g1", Competition: "vqmetro24s1", Division: "MD"}
+	team := subscription.Subscription{GuildID: "g1", Competition: "vqmetro24s1", Division: "MD", Team: "Aces"}
+
+	tests := []struct {
+		name         string
+		subscription subscription.Subscription
+		topic        subscription.Topic
+		want         bool
+	}{
+		{name: "ladder update for the division", subscription: team, topic: subscription.Topic{Competition: "vqmetro24s1", Division: "md"}, want: true},
+		{name: "fixture update for the team", subscription: team, topic: subscription.Topic{Competition: "vqmetro24s1", Division: "MD", Team: "aces"}, want: true},
+		{name: "fixture update for another team", subscription: team, topic: subscription.Topic{Competition: "vqmetro24s1", Division: "MD", Team: "APG"}, want: false},
+		{name: "fixture update without a followed team", subscription: division, topic: subscription.Topic{Competition: "vqmetro24s1", Division: "MD", Team: "Aces"}, want: false},
+		{name: "another division", subscription: team, topic: subscription.Topic{Competition: "vqmetro24s1", Division: "WD"}, want: false},
+		{name: "another competition", subscription: team, topic: subscription.Topic{Competition: "vqmetro24s2", Division: "MD"}, want: false},
+		{name: "disabled", subscription: subscription.Subscription{GuildID: "g1", Disabled: true}, topic: subscription.Topic{}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.subscription.Matches(tt.topic); got != tt.want {
+				t.Errorf("Subscription.Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTopics(t *testing.T) {
+	subscriptions := []subscription.Subscription{
+		{GuildID: "g1", Competition: "vqmetro24s1", Division: "MD", Team: "Aces"},
+		{GuildID: "g2", Competition: "vqmetro24s1", Division: "md", Team: "aces"},
+		{GuildID: "g3", Competition: "vqmetro24s1", Division: "WD"},
+		{GuildID: "g4", Competition: "vqmetro24s1", Division: "XD", Disabled: true},
+	}
+
+	want := []subscription.Topic{
+		{Competition: "vqmetro24s1", Division: "MD"},
+		{Competition: "vqmetro24s1", Division: "MD", Team: "Aces"},
+		{Competition: "vqmetro24s1", Division: "WD"},
+	}
+
+	if got := subscription.Topics(subscriptions); !reflect.DeepEqual(got, want) {
+		t.Errorf("Topics() = %v, want %v", got, want)
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	s := store.NewMemory()
+	registry := subscription.NewRegistry(s, "subscriptions")
+
+	if _, found, err := registry.Get("g1"); found || err != nil {
+		t.Fatalf("Registry.Get() = %v, %v, want nothing before subscribing", found, err)
+	}
+
+	aces := subscription.Subscription{GuildID: "g1", ChannelID: "c1", Competition: "vqmetro24s1", Division: "MD", Team: "Aces"}
+	if err := registry.Subscribe(aces); err != nil {
+		t.Fatalf("Registry.Subscribe() error = %v", err)
+	}
+
+	// replaces the guild's subscription.
+	setters := subscription.Subscription{GuildID: "g2", ChannelID: "c2", Competition: "vqmetro24s1", Division: "WD", Team: "Setters"}
+	aces.Team = "APG"
+	for _, sub := range []subscription.Subscription{setters, aces} {
+		if err := registry.Subscribe(sub); err != nil {
+			t.Fatalf("Registry.Subscribe() error = %v", err)
+		}
+	}
+
+	if err := registry.Subscribe(subscription.Subscription{}); err == nil {
+		t.Errorf("Registry.Subscribe() error = nil, want an error without a guild")
+	}
+
+	// subscriptions survive a new registry on the same store.
+	reopened := subscription.NewRegistry(s, "subscriptions")
+	got, found, err := reopened.Get("g1")
+	if err != nil || !found || !reflect.DeepEqual(got, aces) {
+		t.Errorf("Registry.Get() = %v, %v, %v, want %v", got, found, err, aces)
+	}
+
+	removed, err := reopened.Unsubscribe("g1")
+	if err != nil || !removed {
+		t.Fatalf("Registry.Unsubscribe() = %v, %v, want true", removed, err)
+	}
+
+	if removed, _ := reopened.Unsubscribe("g1"); removed {
+		t.Errorf("Registry.Unsubscribe() = true, want false when already unsubscribed")
+	}
+
+	all, err := reopened.All()
+	want := []subscription.Subscription{{GuildID: "g1", Disabled: true}, setters}
+	if err != nil || !reflect.DeepEqual(all, want) {
+		t.Errorf("Registry.All() = %v, %v, want %v", all, err, want)
+	}
+}
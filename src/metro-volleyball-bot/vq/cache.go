@@ -0,0 +1,155 @@
+package vq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+const defaultCacheMaxStale = 24 * time.Hour
+
+// refreshTimeout bounds a shared refresh. It doesn't use the context of the caller that
+// started it, so a caller giving up doesn't fail the others waiting on it.
+const refreshTimeout = time.Minute
+
+// responseCache stores api responses by request so repeated lookups don't hit softr. A
+// response older than the ttl is revalidated with a conditional request, and served
+// stale for up to maxStale if the api can't be reached.
+type responseCache struct {
+	ttl      time.Duration
+	maxStale time.Duration
+	now      func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+	group   singleflight.Group
+}
+
+type cacheEntry struct {
+	body         []byte
+	etag         string
+	lastModified string
+	storedAt     time.Time
+}
+
+// newResponseCache returns nil when ttl isn't positive, which disables caching.
+func newResponseCache(ttl, maxStale time.Duration) *responseCache {
+	if ttl <= 0 {
+		return nil
+	}
+
+	if maxStale <= 0 {
+		maxStale = defaultCacheMaxStale
+	}
+
+	return &responseCache{
+		ttl:      ttl,
+		maxStale: maxStale,
+		now:      time.Now,
+		entries:  map[string]cacheEntry{},
+	}
+}
+
+// fetch returns the cached body for key while it's fresh. Otherwise refresh is called with
+// the conditional request headers for the cached entry, if there is one. Concurrent
+// fetches for the same key share a single refresh, each caller stops waiting on it when
+// its own context is done. The refresh runs on a context detached from the callers, keeping
+// their values but not their cancellation, and limited to refreshTimeout.
+func (rc *responseCache) fetch(ctx context.Context, key string, refresh func(ctx context.Context, conditional http.Header) (response, error)) ([]byte, error) {
+	if entry, ok := rc.get(key); ok && rc.fresh(entry) {
+		return entry.body, nil
+	}
+
+	detached := context.WithoutCancel(ctx)
+	results := rc.group.DoChan(key, func() (any, error) {
+		ctx, cancel := context.WithTimeout(detached, refreshTimeout)
+		defer cancel()
+
+		entry, cached := rc.get(key)
+		// another caller may have refreshed the entry while we were waiting.
+		if cached && rc.fresh(entry) {
+			return entry.body, nil
+		}
+
+		conditional := http.Header{}
+		if cached && entry.etag != "" {
+			conditional.Set("If-None-Match", entry.etag)
+		}
+		if cached && entry.lastModified != "" {
+			conditional.Set("If-Modified-Since", entry.lastModified)
+		}
+
+		res, err := refresh(ctx, conditional)
+		if err != nil {
+			if cached && rc.now().Sub(entry.storedAt) <= rc.ttl+rc.maxStale {
+				slog.Warn("serving stale response, refresh failed", "error", err, "age", rc.now().Sub(entry.storedAt).String())
+				return entry.body, nil
+			}
+
+			return nil, err
+		}
+
+		if res.notModified {
+			if !cached {
+				return nil, errors.New("fetch() not modified response for a request that isn't cached")
+			}
+
+			entry.storedAt = rc.now()
+			rc.set(key, entry)
+			return entry.body, nil
+		}
+
+		rc.set(key, cacheEntry{
+			body:         res.body,
+			etag:         res.etag,
+			lastModified: res.lastModified,
+			storedAt:     rc.now(),
+		})
+
+		return res.body, nil
+	})
+
+	select {
+	case result := <-results:
+		if result.Err != nil {
+			return nil, result.Err
+		}
+
+		return result.Val.([]byte), nil
+	case <-ctx.Done():
+		return nil, fmt.Errorf("fetch() stopped waiting for response: %w", ctx.Err())
+	}
+}
+
+func (rc *responseCache) fresh(entry cacheEntry) bool {
+	return rc.now().Sub(entry.storedAt) < rc.ttl
+}
+
+func (rc *responseCache) get(key string) (cacheEntry, bool) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	entry, ok := rc.entries[key]
+	return entry, ok
+}
+
+// set stores the entry and drops any entries too old to be served, even stale.
+func (rc *responseCache) set(key string, entry cacheEntry) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	now := rc.now()
+	for k, e := range rc.entries {
+		if now.Sub(e.storedAt) > rc.ttl+rc.maxStale {
+			delete(rc.entries, k)
+		}
+	}
+
+	rc.entries[key] = entry
+}
@@ -0,0 +1,252 @@
+package vq_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/brewinski/home-lab-server/src/metro-volleyball-bot/vq"
+)
+
+func TestClient_Cache(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		// handler is called with the request number, starting at 1.
+		handler      func(request int32, w http.ResponseWriter, r *http.Request)
+		ttl          time.Duration
+		requests     func(c *vq.Client) error
+		wantRequests int32
+		wantErr      bool
+	}{
+		{
+			name: "repeated requests within the ttl are served from the cache",
+			handler: func(request int32, w http.ResponseWriter, r *http.Request) {
+				json.NewEncoder(w).Encode(ladderResponseBody)
+			},
+			ttl: time.Minute,
+			requests: func(c *vq.Client) error {
+				for i := 0; i < 3; i++ {
+					if _, err := c.GetLadder(context.Background()); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+			wantRequests: 1,
+		},
+		{
+			name: "requests with different bodies are cached separately",
+			handler: func(request int32, w http.ResponseWriter, r *http.Request) {
+				json.NewEncoder(w).Encode(ladderResponseBody)
+			},
+			ttl: time.Minute,
+			requests: func(c *vq.Client) error {
+				if _, err := c.GetLadderPage(context.Background(), 1, ""); err != nil {
+					return err
+				}
+				_, err := c.GetLadderPage(context.Background(), 2, "")
+				return err
+			},
+			wantRequests: 2,
+		},
+		{
+			name: "expired responses are served stale when the refresh fails",
+			handler: func(request int32, w http.ResponseWriter, r *http.Request) {
+				if request > 1 {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				json.NewEncoder(w).Encode(ladderResponseBody)
+			},
+			ttl: time.Millisecond,
+			requests: func(c *vq.Client) error {
+				if _, err := c.GetLadder(context.Background()); err != nil {
+					return err
+				}
+
+				time.Sleep(5 * time.Millisecond)
+
+				ladder, err := c.GetLadder(context.Background())
+				if err == nil && len(ladder.Records) != len(ladderResponseBody.Records) {
+					t.Errorf("Client.GetLadder() stale = %v, want %v", ladder, ladderResponseBody)
+				}
+				return err
+			},
+			wantRequests: 2,
+		},
+		{
+			name: "failures are returned when nothing is cached",
+			handler: func(request int32, w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			},
+			ttl: time.Minute,
+			requests: func(c *vq.Client) error {
+				_, err := c.GetLadder(context.Background())
+				return err
+			},
+			wantRequests: 1,
+			wantErr:      true,
+		},
+		{
+			name: "expired responses are revalidated with a conditional request",
+			handler: func(request int32, w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("If-None-Match") == `"v1"` {
+					w.WriteHeader(http.StatusNotModified)
+					return
+				}
+				w.Header().Set("ETag", `"v1"`)
+				json.NewEncoder(w).Encode(ladderResponseBody)
+			},
+			ttl: time.Millisecond,
+			requests: func(c *vq.Client) error {
+				if _, err := c.GetLadder(context.Background()); err != nil {
+					return err
+				}
+
+				time.Sleep(5 * time.Millisecond)
+
+				ladder, err := c.GetLadder(context.Background())
+				if err == nil && len(ladder.Records) != len(ladderResponseBody.Records) {
+					t.Errorf("Client.GetLadder() revalidated = %v, want %v", ladder, ladderResponseBody)
+				}
+				return err
+			},
+			wantRequests: 2,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var requests atomic.Int32
+			testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				tt.handler(requests.Add(1), w, r)
+			}))
+			defer testServer.Close()
+
+			c := vq.NewClient(vq.ClientConfig{
+				Client:     &http.Client{},
+				ApiUrl:     testServer.URL,
+				MaxRetries: -1,
+				CacheTTL:   tt.ttl,
+			})
+
+			err := tt.requests(c)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("requests error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if got := requests.Load(); got != tt.wantRequests {
+				t.Errorf("server requests = %d, want %d", got, tt.wantRequests)
+			}
+		})
+	}
+}
+
+func TestClient_CacheSingleFlight(t *testing.T) {
+	t.Parallel()
+
+	release := make(chan struct{})
+	var requests atomic.Int32
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		// hold the first request open until every caller is waiting on it.
+		<-release
+		json.NewEncoder(w).Encode(ladderResponseBody)
+	}))
+	defer testServer.Close()
+
+	c := vq.NewClient(vq.ClientConfig{
+		Client:   &http.Client{},
+		ApiUrl:   testServer.URL,
+		CacheTTL: time.Minute,
+	})
+
+	workers := 10
+	var wg sync.WaitGroup
+	wg.Add(workers)
+
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			if _, err := c.GetLadder(context.Background()); err != nil {
+				t.Errorf("Client.GetLadder() error = %v", err)
+			}
+		}()
+	}
+
+	// give the callers time to join the in-flight request before it completes.
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if got := requests.Load(); got != 1 {
+		t.Errorf("server requests = %d, want 1", got)
+	}
+}
+
+func TestClient_CacheSingleFlightCancelledLeader(t *testing.T) {
+	t.Parallel()
+
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	var requests atomic.Int32
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		started <- struct{}{}
+		<-release
+		json.NewEncoder(w).Encode(ladderResponseBody)
+	}))
+	defer testServer.Close()
+
+	c := vq.NewClient(vq.ClientConfig{
+		Client:   &http.Client{},
+		ApiUrl:   testServer.URL,
+		CacheTTL: time.Minute,
+	})
+
+	// the leading caller starts the request and gives up while it's in flight.
+	leaderCtx, cancelLeader := context.WithCancel(context.Background())
+	leaderErr := make(chan error, 1)
+	go func() {
+		_, err := c.GetLadder(leaderCtx)
+		leaderErr <- err
+	}()
+	<-started
+
+	follower := make(chan error, 1)
+	go func() {
+		ladder, err := c.GetLadder(context.Background())
+		if err == nil && !reflect.DeepEqual(ladder, ladderResponseBody) {
+			err = fmt.Errorf("got %v, want %v", ladder, ladderResponseBody)
+		}
+		follower <- err
+	}()
+
+	// give the follower time to join the in-flight request before the leader gives up.
+	time.Sleep(50 * time.Millisecond)
+	cancelLeader()
+	if err := <-leaderErr; !errors.Is(err, context.Canceled) {
+		t.Errorf("Client.GetLadder() leader error = %v, want %v", err, context.Canceled)
+	}
+
+	close(release)
+	if err := <-follower; err != nil {
+		t.Errorf("Client.GetLadder() follower error = %v, want the ladder", err)
+	}
+
+	if got := requests.Load(); got != 1 {
+		t.Errorf("server requests = %d, want 1", got)
+	}
+}
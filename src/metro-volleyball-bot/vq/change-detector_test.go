@@ -1,9 +1,11 @@
 package vq_test
 
 import (
+	"context"
 	"testing"
 
 	"github.com/brewinski/home-lab-server/src/metro-volleyball-bot/vq"
+	"github.com/brewinski/home-lab-server/src/metro-volleyball-bot/vq/vqtest"
 )
 
 func TestDetectLadderChanges(t *testing.T) {
@@ -163,3 +165,36 @@ func TestDetectLadderChanges(t *testing.T) {
 		})
 	}
 }
+
+func TestDetectLadderChanges_Server(t *testing.T) {
+	s := vqtest.NewServer(t)
+	client := s.NewClient()
+
+	old, err := client.GetLadder(context.Background())
+	if err != nil {
+		t.Fatalf("Client.GetLadder() error = %v", err)
+	}
+
+	unchanged, err := client.GetLadder(context.Background())
+	if err != nil {
+		t.Fatalf("Client.GetLadder() error = %v", err)
+	}
+
+	if vq.DetectLadderChanges(old, unchanged) {
+		t.Errorf("DetectLadderChanges() = true, want false for an unchanged ladder")
+	}
+
+	// swap the top two teams.
+	ladder := vqtest.LadderFixture(t)
+	ladder[0].Fields.Rank, ladder[1].Fields.Rank = ladder[1].Fields.Rank, ladder[0].Fields.Rank
+	s.SetLadder(ladder)
+
+	changed, err := client.GetLadder(context.Background())
+	if err != nil {
+		t.Fatalf("Client.GetLadder() error = %v", err)
+	}
+
+	if !vq.DetectLadderChanges(old, changed) {
+		t.Errorf("DetectLadderChanges() = false, want true after the ranks changed")
+	}
+}
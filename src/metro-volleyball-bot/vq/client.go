@@ -2,24 +2,22 @@ package vq
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/brewinski/home-lab-server/src/metro-volleyball-bot/cfg"
-)
-
-const (
-	// TODO: replace api url with vq api url / env var
-	apiUrl = "https://vqmetro23s3.softr.app/v1/integrations/airtable/67a0cea2-90f1-4d07-8903-89cda40f4264/appdBNmBQcBRBqB3P/Competition%20Manager/records?block_id=77226c67-17e8-4238-b983-db7105c48dfe"
-	// TODO: use these vars combined to document the api and paths.
-	vqAPIUrl        = "https://vqmetro23s3.softr.app/v1/integrations/airtable/67a0cea2-90f1-4d07-8903-89cda40f4264/appdBNmBQcBRBqB3P"
-	competitionPath = "/Competition%20Manager/records?block_id=77226c67-17e8-4238-b983-db7105c48dfe"
+	"github.com/brewinski/home-lab-server/src/metro-volleyball-bot/vq/formula"
 )
 
 var (
+	// shared between requests, never modify it directly. use newRequestHeaders to get a copy.
 	defaultRequestHeaders = http.Header{
 		"accept":             {"application/json, text/plain, */*"},
 		"accept-language":    {"en-GB,en-US;q=0.9,en;q=0.8"},
@@ -33,14 +31,54 @@ var (
 	}
 )
 
+// ErrNoGames is returned by the games requests of a client without a games endpoint.
+var ErrNoGames = errors.New("no games endpoint configured")
+
 type Client struct {
-	client *http.Client
-	apiUrl string
+	client       *http.Client
+	apiUrl       string
+	ladderPath   string
+	ladderPageID string
+	gamesPath    string
+	gamesPageID  string
+	division     string
+	retry        retryPolicy
+	cache        *responseCache
 }
 
 type ClientConfig struct {
 	Client *http.Client
 	ApiUrl string
+	// LadderPath is appended to ApiUrl to request the ladder. defaults to the ladder path
+	// of cfg.DefaultCompetition.
+	LadderPath string
+	// LadderPageID is sent as the softr-page-id header with ladder requests. defaults to
+	// the ladder page id of cfg.DefaultCompetition.
+	LadderPageID string
+	// GamesPath is appended to ApiUrl to request games. defaults to the games endpoint of
+	// cfg.DefaultCompetition when it has one. games requests return ErrNoGames without it.
+	GamesPath string
+	// GamesPageID is sent as the softr-page-id header with games requests. defaults along
+	// with GamesPath.
+	GamesPageID string
+	// Division is the division code the ladder is filtered to. defaults to the division
+	// of cfg.DefaultCompetition.
+	Division string
+	// MaxRetries is the number of times a request is retried after a network error, a 429
+	// or a 5xx response. defaults to 3, set it to a negative number to disable retries.
+	MaxRetries int
+	// RetryBaseDelay is the delay before the first retry. it doubles for every retry after
+	// that. defaults to 500ms.
+	RetryBaseDelay time.Duration
+	// RetryMaxDelay caps the delay between retries, including a longer Retry-After header
+	// from the server. defaults to 10s.
+	RetryMaxDelay time.Duration
+	// CacheTTL enables response caching when it's greater than zero. Identical requests
+	// made within the ttl are answered from the cache without calling the api.
+	CacheTTL time.Duration
+	// CacheMaxStale is how long after the ttl a cached response can still be served when
+	// refreshing it fails. defaults to 24h when caching is enabled.
+	CacheMaxStale time.Duration
 }
 
 func NewClient(config ClientConfig) *Client {
@@ -50,13 +88,40 @@ func NewClient(config ClientConfig) *Client {
 		}
 	}
 
+	if config.LadderPath == "" {
+		config.LadderPath = cfg.DefaultCompetition.Ladder.Path()
+	}
+
+	if config.LadderPageID == "" {
+		config.LadderPageID = cfg.DefaultCompetition.Ladder.PageID
+	}
+
+	// an endpoint without a block id would query every block, so only a configured one
+	// is used.
+	if config.GamesPath == "" && config.GamesPageID == "" && cfg.DefaultCompetition.HasGames() {
+		config.GamesPath = cfg.DefaultCompetition.Games.Path()
+		config.GamesPageID = cfg.DefaultCompetition.Games.PageID
+	}
+
+	if config.Division == "" {
+		config.Division = cfg.DefaultCompetition.Division
+	}
+
 	return &Client{
 		config.Client,
 		config.ApiUrl,
+		config.LadderPath,
+		config.LadderPageID,
+		config.GamesPath,
+		config.GamesPageID,
+		config.Division,
+		newRetryPolicy(config.MaxRetries, config.RetryBaseDelay, config.RetryMaxDelay),
+		newResponseCache(config.CacheTTL, config.CacheMaxStale),
 	}
 }
 
-func (c *Client) GetGamesByTeamAndDuty(limit int, offset, team string) (GetGameResponseBody, error) {
+// get games where the team is on duty with a limit and an offset. maximum limit is 100.
+func (c *Client) GetGamesByTeamAndDuty(ctx context.Context, limit int, offset, team string) (GetGameResponseBody, error) {
 	gameReqBody := GetGamesRequestBody{
 		PageSize: limit,
 		AirtableResponseFormatting: struct {
@@ -66,13 +131,14 @@ func (c *Client) GetGamesByTeamAndDuty(limit int, offset, team string) (GetGameR
 		},
 		View:            "RMS - Timeslot",
 		Offset:          offset,
-		FilterByFormula: fmt.Sprintf("AND(OR(SEARCH(LOWER(\"%s\"), LOWER(ARRAYJOIN({LinkTmD})))))", team)}
+		FilterByFormula: formula.Contains("LinkTmD", team).String(),
+	}
 
-	return c.getGames(gameReqBody)
+	return c.getGames(ctx, gameReqBody)
 }
 
 // get games filtered by team with a limit and an offset. maximum limit is 100.
-func (c *Client) GetGamesByTeam(limit int, offset, team string) (GetGameResponseBody, error) {
+func (c *Client) GetGamesByTeam(ctx context.Context, limit int, offset, team string) (GetGameResponseBody, error) {
 	gameReqBody := GetGamesRequestBody{
 		PageSize: limit,
 		AirtableResponseFormatting: struct {
@@ -82,15 +148,36 @@ func (c *Client) GetGamesByTeam(limit int, offset, team string) (GetGameResponse
 		},
 		View:            "RMS - Timeslot",
 		Offset:          offset,
-		FilterByFormula: fmt.Sprintf("AND(OR(SEARCH(\"%s\", LOWER(ARRAYJOIN({Display_Identifier})))))", team),
+		FilterByFormula: formula.Contains("Display_Identifier", team).String(),
 	}
 
-	return c.getGames(gameReqBody)
+	return c.getGames(ctx, gameReqBody)
+}
+
+// GetGamesBetween returns a page of the games where the two teams play each other,
+// whichever side of the draw they're on.
+func (c *Client) GetGamesBetween(ctx context.Context, limit int, offset, teamA, teamB string) (GetGameResponseBody, error) {
+	gameReqBody := GetGamesRequestBody{
+		PageSize: limit,
+		AirtableResponseFormatting: struct {
+			Format string `json:"format"`
+		}{
+			Format: "string",
+		},
+		View:   "RMS - Timeslot",
+		Offset: offset,
+		FilterByFormula: formula.Or(
+			formula.And(formula.Contains("LinkTmA", teamA), formula.Contains("LinkTmB", teamB)),
+			formula.And(formula.Contains("LinkTmA", teamB), formula.Contains("LinkTmB", teamA)),
+		).String(),
+	}
+
+	return c.getGames(ctx, gameReqBody)
 }
 
 // get all games with a limit and an offset. maximum limit is 100.
-func (c *Client) GetGames(limit int, offset string) (GetGameResponseBody, error) {
-	return c.getGames(GetGamesRequestBody{
+func (c *Client) GetGames(ctx context.Context, limit int, offset string) (GetGameResponseBody, error) {
+	return c.getGames(ctx, GetGamesRequestBody{
 		PageSize: limit,
 		AirtableResponseFormatting: struct {
 			Format string `json:"format"`
@@ -102,90 +189,259 @@ func (c *Client) GetGames(limit int, offset string) (GetGameResponseBody, error)
 	})
 }
 
-func (c *Client) getGames(reqBody GetGamesRequestBody) (GetGameResponseBody, error) {
-	gameRequestBody, err := json.Marshal(reqBody)
-	if err != nil {
-		return GetGameResponseBody{}, fmt.Errorf("GetGames() request failed, got: %w", err)
+// AllGames returns every game, following the offset cursor until all pages are read.
+func (c *Client) AllGames(ctx context.Context) ([]GameRecord, error) {
+	return collectPages(func(offset string) ([]GameRecord, string, error) {
+		games, err := c.GetGames(ctx, maxPageSize, offset)
+		return games.Records, games.Offset, err
+	})
+}
+
+// AllGamesByTeam returns every game for the team, following the offset cursor until all
+// pages are read.
+func (c *Client) AllGamesByTeam(ctx context.Context, team string) ([]GameRecord, error) {
+	return collectPages(func(offset string) ([]GameRecord, string, error) {
+		games, err := c.GetGamesByTeam(ctx, maxPageSize, offset, team)
+		return games.Records, games.Offset, err
+	})
+}
+
+// AllGamesBetween returns every game where the two teams play each other, following the
+// offset cursor until all pages are read.
+func (c *Client) AllGamesBetween(ctx context.Context, teamA, teamB string) ([]GameRecord, error) {
+	return collectPages(func(offset string) ([]GameRecord, string, error) {
+		games, err := c.GetGamesBetween(ctx, maxPageSize, offset, teamA, teamB)
+		return games.Records, games.Offset, err
+	})
+}
+
+// AllGamesByTeamAndDuty returns every game the team is on duty for, following the offset
+// cursor until all pages are read.
+func (c *Client) AllGamesByTeamAndDuty(ctx context.Context, team string) ([]GameRecord, error) {
+	return collectPages(func(offset string) ([]GameRecord, string, error) {
+		games, err := c.GetGamesByTeamAndDuty(ctx, maxPageSize, offset, team)
+		return games.Records, games.Offset, err
+	})
+}
+
+// HasGames reports whether the client has a games endpoint to request games from.
+func (c *Client) HasGames() bool {
+	return c.gamesPath != "" && c.gamesPageID != ""
+}
+
+func (c *Client) getGames(ctx context.Context, reqBody GetGamesRequestBody) (GetGameResponseBody, error) {
+	if !c.HasGames() {
+		return GetGameResponseBody{}, fmt.Errorf("GetGames() unable to request games, got: %w", ErrNoGames)
 	}
 
-	request, err := http.NewRequest(http.MethodPost, apiUrl, bytes.NewBuffer(gameRequestBody))
+	body, err := c.post(ctx, c.apiUrl+c.gamesPath, c.gamesPageID, reqBody)
 	if err != nil {
 		return GetGameResponseBody{}, fmt.Errorf("GetGames() request failed, got: %w", err)
 	}
 
-	request.Header = defaultRequestHeaders
-	request.Header.Set("softr-page-id", "f787ee5d-5f9d-4fe1-95f4-22a2ceeb4d01")
+	var games GetGameResponseBody
 
-	res, err := c.client.Do(request)
+	err = json.Unmarshal(body, &games)
 	if err != nil {
 		return GetGameResponseBody{}, fmt.Errorf("GetGames() request failed, got: %w", err)
 	}
+	return games, nil
+}
+
+// GetLadder returns the full ladder of the client's division, following the offset cursor
+// until every team has been read. The returned offset is always empty.
+func (c *Client) GetLadder(ctx context.Context) (GetLadderResponseBody, error) {
+	ladder, err := c.GetDivisionLadder(ctx, c.division)
+	if err != nil {
+		return GetLadderResponseBody{}, fmt.Errorf("GetLadder() request failed, got: %w", err)
+	}
 
-	body, err := io.ReadAll(res.Body)
+	return ladder, nil
+}
+
+// GetDivisionLadder returns the full ladder of the division. An empty division returns the
+// teams of every division in the competition.
+func (c *Client) GetDivisionLadder(ctx context.Context, division string) (GetLadderResponseBody, error) {
+	records, err := collectPages(func(offset string) ([]LadderRecord, string, error) {
+		ladder, err := c.getLadderPage(ctx, division, maxPageSize, offset)
+		return ladder.Records, ladder.Offset, err
+	})
 	if err != nil {
-		return GetGameResponseBody{}, fmt.Errorf("GetGames() request failed, got: %w", err)
+		return GetLadderResponseBody{}, fmt.Errorf("GetDivisionLadder() request failed, got: %w", err)
 	}
 
-	defer res.Body.Close()
+	return GetLadderResponseBody{Records: records}, nil
+}
 
-	var games GetGameResponseBody
+// Division is the division the client follows.
+func (c *Client) Division() string {
+	return c.division
+}
 
-	err = json.Unmarshal(body, &games)
+// Divisions returns the divisions in the competition in alphabetical order.
+func (c *Client) Divisions(ctx context.Context) ([]string, error) {
+	ladder, err := c.GetDivisionLadder(ctx, "")
 	if err != nil {
-		return GetGameResponseBody{}, fmt.Errorf("GetGames() request failed, got: %w", err)
+		return nil, fmt.Errorf("Divisions() request failed, got: %w", err)
 	}
-	return games, nil
+
+	seen := map[string]bool{}
+	var divisions []string
+	for _, record := range ladder.Records {
+		division := strings.TrimSpace(record.Fields.Division)
+		if division == "" || seen[division] {
+			continue
+		}
+
+		seen[division] = true
+		divisions = append(divisions, division)
+	}
+	sort.Strings(divisions)
+
+	return divisions, nil
+}
+
+// get a single page of the ladder with a limit and an offset. maximum limit is 100.
+func (c *Client) GetLadderPage(ctx context.Context, limit int, offset string) (GetLadderResponseBody, error) {
+	return c.getLadderPage(ctx, c.division, limit, offset)
 }
 
-func (c *Client) GetLadder() (GetLadderResponseBody, error) {
-	ladderRequestBody, err := json.Marshal(GetLadderRequestBody{
-		PageSize: 100,
+func (c *Client) getLadderPage(ctx context.Context, division string, limit int, offset string) (GetLadderResponseBody, error) {
+	filter := ""
+	if division != "" {
+		filter = formula.Equals("Division", division).String()
+	}
+
+	body, err := c.post(ctx, c.apiUrl+c.ladderPath, c.ladderPageID, GetLadderRequestBody{
+		PageSize: limit,
 		AirtableResponseFormatting: struct {
 			Format string `json:"format"`
 		}{
 			Format: "string",
 		},
 		View:            "Division Ranking",
-		FilterByFormula: cfg.VQLadderFilterByFormula,
+		FilterByFormula: filter,
 		Rows:            0,
-		Offset:          "",
+		Offset:          offset,
 	})
+	if err != nil {
+		return GetLadderResponseBody{}, fmt.Errorf("GetLadderPage() request failed, got: %w", err)
+	}
 
+	var ladder GetLadderResponseBody
+
+	err = json.Unmarshal(body, &ladder)
 	if err != nil {
-		return GetLadderResponseBody{}, fmt.Errorf("GetLadder() request failed, got: %w", err)
+		return GetLadderResponseBody{}, fmt.Errorf("GetLadderPage() request failed, got: %w", err)
 	}
 
-	request, err := http.NewRequest(http.MethodPost, c.apiUrl+cfg.VQLadderPath, bytes.NewBuffer(ladderRequestBody))
+	return ladder, nil
+}
+
+// post sends reqBody as json to the url and returns the response body. When the client
+// has a cache, fresh responses are served from it and identical concurrent requests share
+// a single upstream call.
+func (c *Client) post(ctx context.Context, url string, pageID string, reqBody any) ([]byte, error) {
+	requestBody, err := json.Marshal(reqBody)
 	if err != nil {
-		return GetLadderResponseBody{}, fmt.Errorf("GetLadder() request failed, got: %w", err)
+		return nil, fmt.Errorf("post() unable to encode request body, got: %w", err)
 	}
 
-	request.Header = defaultRequestHeaders
-	request.Header.Set("softr-page-id", cfg.VQLadderPageID)
+	if c.cache == nil {
+		res, err := c.postWithRetries(ctx, url, pageID, requestBody, nil)
+		return res.body, err
+	}
 
-	res, err := c.client.Do(request)
+	key := url + "\n" + pageID + "\n" + string(requestBody)
+
+	return c.cache.fetch(ctx, key, func(ctx context.Context, conditional http.Header) (response, error) {
+		return c.postWithRetries(ctx, url, pageID, requestBody, conditional)
+	})
+}
+
+// postWithRetries sends the request, retrying network errors, 429 and 5xx responses
+// according to the client's retry policy until the retries are exhausted or the context
+// is done.
+func (c *Client) postWithRetries(ctx context.Context, url string, pageID string, requestBody []byte, header http.Header) (response, error) {
+	for attempt := 0; ; attempt++ {
+		res, retryAfter, err := c.send(ctx, url, pageID, requestBody, header)
+		if err == nil {
+			return res, nil
+		}
+
+		if !isRetryable(err) || attempt >= c.retry.maxRetries {
+			return response{}, err
+		}
+
+		if waitErr := sleep(ctx, c.retry.delay(attempt, retryAfter)); waitErr != nil {
+			return response{}, fmt.Errorf("post() gave up after %d attempts: %w, last error: %w", attempt+1, waitErr, err)
+		}
+	}
+}
+
+// response is a successful reply from the api.
+type response struct {
+	body []byte
+	// etag and lastModified are the validators used to make conditional requests.
+	etag         string
+	lastModified string
+	// notModified is set when a conditional request found the cached body is still current.
+	notModified bool
+}
+
+// send makes a single request with any extra headers added. It returns the Retry-After
+// delay requested by the server alongside any error so the caller can decide how long to
+// wait before trying again.
+func (c *Client) send(ctx context.Context, url string, pageID string, requestBody []byte, header http.Header) (response, time.Duration, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(requestBody))
 	if err != nil {
-		return GetLadderResponseBody{}, fmt.Errorf("GetLadder() request failed, got: %w", err)
+		return response{}, 0, fmt.Errorf("send() unable to create request, got: %w", err)
 	}
 
-	if res.StatusCode > 399 {
-		body, _ := io.ReadAll(res.Body)
-		return GetLadderResponseBody{}, fmt.Errorf("GetLadder() request failed with status code %d, response body: %s", res.StatusCode, body)
+	request.Header = newRequestHeaders()
+	request.Header.Set("softr-page-id", pageID)
+	for key, values := range header {
+		request.Header[key] = values
 	}
 
-	body, err := io.ReadAll(res.Body)
+	res, err := c.client.Do(request)
 	if err != nil {
-		return GetLadderResponseBody{}, fmt.Errorf("GetLadder() request failed, got: %w", err)
+		// errors caused by the context ending are not worth retrying.
+		if ctx.Err() != nil {
+			return response{}, 0, fmt.Errorf("send() request failed, got: %w", err)
+		}
+
+		return response{}, 0, retryableError{fmt.Errorf("send() request failed, got: %w", err)}
 	}
 
 	defer res.Body.Close()
 
-	var ladder GetLadderResponseBody
-
-	err = json.Unmarshal(body, &ladder)
+	body, err := io.ReadAll(res.Body)
 	if err != nil {
-		return GetLadderResponseBody{}, fmt.Errorf("GetLadder() request failed, got: %w", err)
+		return response{}, 0, retryableError{fmt.Errorf("send() unable to read response body, got: %w", err)}
 	}
 
-	return ladder, nil
+	if res.StatusCode == http.StatusNotModified {
+		return response{notModified: true}, 0, nil
+	}
+
+	if res.StatusCode > 399 {
+		err := fmt.Errorf("send() request failed with status code %d, response body: %s", res.StatusCode, body)
+		if res.StatusCode == http.StatusTooManyRequests || res.StatusCode >= 500 {
+			return response{}, parseRetryAfter(res.Header.Get("Retry-After"), time.Now()), retryableError{err}
+		}
+
+		return response{}, 0, err
+	}
+
+	return response{
+		body:         body,
+		etag:         res.Header.Get("ETag"),
+		lastModified: res.Header.Get("Last-Modified"),
+	}, 0, nil
+}
+
+// newRequestHeaders returns a copy of the default headers that is safe to modify.
+func newRequestHeaders() http.Header {
+	return defaultRequestHeaders.Clone()
 }
@@ -1,63 +1,64 @@
 package vq_test
 
 import (
+	"context"
 	"encoding/json"
-	"fmt"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
 	"strings"
+	"sync/atomic"
 	"testing"
 
-	"github.com/brewinski/home-lab-server/src/metro-volleyball-bot/cfg"
 	"github.com/brewinski/home-lab-server/src/metro-volleyball-bot/vq"
+	"github.com/brewinski/home-lab-server/src/metro-volleyball-bot/vq/vqtest"
 )
 
-// TODO: with fake responses test get games for team.
-
-func TestClient_GetGames_Integration(t *testing.T) {
-	type fields struct {
-		client *http.Client
-	}
+func TestClient_GetGames(t *testing.T) {
 	tests := []struct {
 		name    string
-		fields  fields
-		want    vq.GetGameResponseBody
+		limit   int
 		wantErr bool
 	}{
 		{
-			name: "TestClient_GetGames will return a list of games",
-			fields: fields{
-				client: &http.Client{},
-			},
-			wantErr: false,
+			name:  "TestClient_GetGames will return a list of games",
+			limit: 2,
+		},
+		{
+			name:    "TestClient_GetGames will return an error when the limit is over 100",
+			limit:   101,
+			wantErr: true,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			c := vq.NewClient(vq.ClientConfig{
-				Client: tt.fields.client,
-			})
-			got, err := c.GetGames(2, "")
+			c := vqtest.NewServer(t).NewClient()
+
+			got, err := c.GetGames(context.Background(), tt.limit, "")
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Client.GetGames() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
 
-			if len(got.Records) > 2 {
-				t.Errorf("Client.GetGames() length is greater than requested limit limit = 2, got %d", len(got.Records))
+			if tt.wantErr {
+				return
+			}
+
+			if len(got.Records) != tt.limit {
+				t.Errorf("Client.GetGames() length = %d, want the requested limit %d", len(got.Records), tt.limit)
 				return
 			}
 
-			// if we didn't get an offset we can't test it.
 			if got.Offset == "" {
+				t.Errorf("Client.GetGames() expected an offset for the next page")
 				return
 			}
 
 			// test the offset works.
-			gotOffset, err := c.GetGames(2, got.Offset)
-			if (err != nil) != tt.wantErr {
-				t.Errorf("Client.GetGames() error = %v, wantErr %v", err, tt.wantErr)
+			gotOffset, err := c.GetGames(context.Background(), tt.limit, got.Offset)
+			if err != nil {
+				t.Errorf("Client.GetGames() error = %v", err)
 				return
 			}
 
@@ -65,15 +66,11 @@ func TestClient_GetGames_Integration(t *testing.T) {
 				t.Errorf("Client.GetGames() should be different got = %v, want %v", got, gotOffset)
 				return
 			}
-
 		})
 	}
 }
 
-func TestClient_GetGamesByTeam_Integration(t *testing.T) {
-	type fields struct {
-		client *http.Client
-	}
+func TestClient_GetGamesByTeam(t *testing.T) {
 	type args struct {
 		limit  int
 		offset string
@@ -81,36 +78,33 @@ func TestClient_GetGamesByTeam_Integration(t *testing.T) {
 	}
 	tests := []struct {
 		name    string
-		fields  fields
 		args    args
-		want    vq.GetGameResponseBody
 		wantErr bool
 	}{
 		{
-			name: "TestClient_GetGames will return a list of games",
-			fields: fields{
-				client: &http.Client{},
-			},
+			name: "TestClient_GetGamesByTeam will return the team's games",
 			args: args{
 				limit:  5,
 				offset: "",
 				team:   "aces",
 			},
-			want:    vq.GetGameResponseBody{},
 			wantErr: false,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			c := vq.NewClient(vq.ClientConfig{
-				Client: tt.fields.client,
-			})
-			got, err := c.GetGamesByTeam(tt.args.limit, tt.args.offset, tt.args.team)
+			c := vqtest.NewServer(t).NewClient()
+
+			got, err := c.GetGamesByTeam(context.Background(), tt.args.limit, tt.args.offset, tt.args.team)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Client.GetGamesByTeam() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
 
+			if len(got.Records) == 0 {
+				t.Errorf("Client.GetGamesByTeam() expected games for %s", tt.args.team)
+			}
+
 			// for each game we expect the team to be playing
 			for _, game := range got.Records {
 				lowerTeam := strings.ToLower(tt.args.team)
@@ -118,7 +112,7 @@ func TestClient_GetGamesByTeam_Integration(t *testing.T) {
 				lowerTeamB := strings.ToLower(game.Fields.TeamB)
 
 				if !strings.Contains(lowerTeamA, lowerTeam) && !strings.Contains(lowerTeamB, lowerTeam) {
-					t.Errorf("Client.GetGamesByTeam() error = %v, wantErr %v", err, tt.wantErr)
+					t.Errorf("Client.GetGamesByTeam() returned a game without %s: %+v", tt.args.team, game.Fields)
 					return
 				}
 			}
@@ -126,10 +120,7 @@ func TestClient_GetGamesByTeam_Integration(t *testing.T) {
 	}
 }
 
-func TestClient_GetGamesByTeamAndDuty_Integration(t *testing.T) {
-	type fields struct {
-		client *http.Client
-	}
+func TestClient_GetGamesByTeamAndDuty(t *testing.T) {
 	type args struct {
 		limit  int
 		offset string
@@ -137,16 +128,11 @@ func TestClient_GetGamesByTeamAndDuty_Integration(t *testing.T) {
 	}
 	tests := []struct {
 		name    string
-		fields  fields
 		args    args
-		want    vq.GetGameResponseBody
 		wantErr bool
 	}{
 		{
-			name: "TestClient_GetGames will return a list of games",
-			fields: fields{
-				client: &http.Client{},
-			},
+			name: "TestClient_GetGamesByTeamAndDuty will return the team's duties",
 			args: args{
 				limit:  10,
 				offset: "",
@@ -157,16 +143,18 @@ func TestClient_GetGamesByTeamAndDuty_Integration(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			c := vq.NewClient(vq.ClientConfig{
-				Client: tt.fields.client,
-			})
+			c := vqtest.NewServer(t).NewClient()
 
-			got, err := c.GetGamesByTeamAndDuty(tt.args.limit, tt.args.offset, tt.args.team)
+			got, err := c.GetGamesByTeamAndDuty(context.Background(), tt.args.limit, tt.args.offset, tt.args.team)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Client.GetGamesByTeamAndDuty() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
 
+			if len(got.Records) == 0 {
+				t.Errorf("Client.GetGamesByTeamAndDuty() expected duties for %s", tt.args.team)
+			}
+
 			for _, game := range got.Records {
 				lowerTeam := strings.ToLower(tt.args.team)
 				lowerDutyTeam := strings.ToLower(game.Fields.DutyTeam)
@@ -174,13 +162,13 @@ func TestClient_GetGamesByTeamAndDuty_Integration(t *testing.T) {
 				lowerTeamB := strings.ToLower(game.Fields.TeamB)
 				// for every game we expect the duty team to be the requested team
 				if !strings.Contains(lowerDutyTeam, lowerTeam) {
-					t.Errorf("Client.GetGamesByTeamAndDuty() error = %v, wantErr %v", err, tt.wantErr)
+					t.Errorf("Client.GetGamesByTeamAndDuty() returned a game %s isn't on duty for: %+v", tt.args.team, game.Fields)
 					return
 				}
 
 				// for every game we expect the requested team not to be playing
 				if lowerTeamA == lowerTeam || lowerTeamB == lowerTeam {
-					t.Errorf("Client.GetGamesByTeamAndDuty() error = %v, wantErr %v", err, tt.wantErr)
+					t.Errorf("Client.GetGamesByTeamAndDuty() returned a game %s is playing in: %+v", tt.args.team, game.Fields)
 					return
 				}
 			}
@@ -209,25 +197,18 @@ var ladderResponseBody = vq.GetLadderResponseBody{
 	Offset: "",
 }
 
-func TestClient_GetLadder_Integration(t *testing.T) {
-	type fields struct {
-		// client config
-		client  *http.Client
-		baseUrl string
-		// test server config. (optional) if this is set the test server will be used instead of the base url.
-		testServer *httptest.Server
-	}
+func TestClient_GetLadder(t *testing.T) {
 	tests := []struct {
-		name    string
-		fields  fields
-		want    vq.GetLadderResponseBody
-		wantErr bool
+		name string
+		// client returns the client under test, backed by a fake server.
+		client    func(t *testing.T) *vq.Client
+		wantTeams int
+		wantErr   bool
 	}{
 		{
 			name: "TestClient_GetLadder will return a list of teams",
-			fields: fields{
-				client: &http.Client{},
-				testServer: httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			client: func(t *testing.T) *vq.Client {
+				testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 					if pageId := r.Header.Get("softr-page-id"); pageId == "" {
 						w.WriteHeader(http.StatusBadRequest)
 						w.Write([]byte("missing softr-page-id header"))
@@ -243,46 +224,341 @@ func TestClient_GetLadder_Integration(t *testing.T) {
 
 					w.WriteHeader(http.StatusOK)
 					w.Write(ladderBytes)
-				})),
+				}))
+				t.Cleanup(testServer.Close)
+
+				return vq.NewClient(vq.ClientConfig{
+					Client: &http.Client{},
+					ApiUrl: testServer.URL,
+				})
 			},
-			want:    vq.GetLadderResponseBody{},
-			wantErr: false,
+			wantTeams: len(ladderResponseBody.Records),
 		},
 		{
-			name: "TestClient_GetLadder will return a list of teams",
-			fields: fields{
-				client:  &http.Client{},
-				baseUrl: cfg.VQBaseUrl,
+			name: "TestClient_GetLadder will return the recorded ladder for the division",
+			client: func(t *testing.T) *vq.Client {
+				return vqtest.NewServer(t).NewClient()
 			},
-			want:    vq.GetLadderResponseBody{},
-			wantErr: false,
+			wantTeams: 6,
+		},
+		{
+			name: "TestClient_GetLadder will return an error for malformed responses",
+			client: func(t *testing.T) *vq.Client {
+				s := vqtest.NewServer(t)
+				s.Fail(vqtest.Ladder, vqtest.MalformedJSON)
+				return s.NewClient()
+			},
+			wantErr: true,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			testServer := tt.fields.testServer
-			// we need to close the test server after we are done with it.
-			if testServer != nil {
-				defer testServer.Close()
-				// set the testing url to the url of the test server
-				tt.fields.baseUrl = testServer.URL
+			got, err := tt.client(t).GetLadder(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Client.GetLadder() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if len(got.Records) != tt.wantTeams {
+				t.Errorf("Client.GetLadder() teams = %d, want %d", len(got.Records), tt.wantTeams)
 			}
+		})
+	}
+}
+
+func TestClient_GetLadder_Pagination(t *testing.T) {
+	pages := map[string]vq.GetLadderResponseBody{
+		"": {
+			Records: []vq.LadderRecord{{ID: "1", Fields: vq.LadderFields{Rank: "1", TeamName: "Aces"}}},
+			Offset:  "page-2",
+		},
+		"page-2": {
+			Records: []vq.LadderRecord{{ID: "2", Fields: vq.LadderFields{Rank: "2", TeamName: "APG"}}},
+			Offset:  "page-3",
+		},
+		"page-3": {
+			Records: []vq.LadderRecord{{ID: "3", Fields: vq.LadderFields{Rank: "3", TeamName: "Blockers"}}},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		pages   map[string]vq.GetLadderResponseBody
+		wantIDs []string
+		wantErr bool
+	}{
+		{
+			name:    "GetLadder follows the offset until every page is read",
+			pages:   pages,
+			wantIDs: []string{"1", "2", "3"},
+		},
+		{
+			name: "GetLadder returns an error when the offset repeats",
+			pages: map[string]vq.GetLadderResponseBody{
+				"":       {Records: []vq.LadderRecord{{ID: "1"}}, Offset: "page-2"},
+				"page-2": {Records: []vq.LadderRecord{{ID: "2"}}, Offset: "page-2"},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var reqBody vq.GetLadderRequestBody
+				if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil {
+					w.WriteHeader(http.StatusBadRequest)
+					return
+				}
+
+				if reqBody.PageSize > 100 {
+					w.WriteHeader(http.StatusBadRequest)
+					return
+				}
+
+				page, ok := tt.pages[reqBody.Offset]
+				if !ok {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+
+				json.NewEncoder(w).Encode(page)
+			}))
+			defer testServer.Close()
 
 			c := vq.NewClient(vq.ClientConfig{
-				Client: tt.fields.client,
-				ApiUrl: tt.fields.baseUrl,
+				Client: &http.Client{},
+				ApiUrl: testServer.URL,
 			})
 
-			got, err := c.GetLadder()
+			got, err := c.GetLadder(context.Background())
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Client.GetLadder() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
 
-			for _, team := range got.Records {
-				fmt.Printf("rank[%s], team[%s], points[%s], next_game[%s]\n\n", team.Fields.Rank, team.Fields.TeamNameLookup, team.Fields.CompetitionPoints, team.Fields.NextMatch_Detail)
+			var gotIDs []string
+			for _, record := range got.Records {
+				gotIDs = append(gotIDs, record.ID)
+			}
+
+			if !reflect.DeepEqual(gotIDs, tt.wantIDs) {
+				t.Errorf("Client.GetLadder() ids = %v, want %v", gotIDs, tt.wantIDs)
+			}
+			if got.Offset != "" {
+				t.Errorf("Client.GetLadder() offset = %v, want empty", got.Offset)
+			}
+		})
+	}
+}
+
+func TestClient_GamesEndpoint(t *testing.T) {
+	pages := map[string]vq.GetGameResponseBody{
+		"": {
+			Records: []vq.GameRecord{{ID: "1", Fields: vq.GameFields{TeamA: "Aces", TeamB: "APG"}}},
+			Offset:  "page-2",
+		},
+		"page-2": {
+			Records: []vq.GameRecord{{ID: "2", Fields: vq.GameFields{TeamA: "APG", TeamB: "Aces"}}},
+		},
+	}
+
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/Games/records" || r.URL.Query().Get("block_id") != "games-block" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		if pageId := r.Header.Get("softr-page-id"); pageId != "games-page" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte("unexpected softr-page-id header"))
+			return
+		}
+
+		var reqBody vq.GetGamesRequestBody
+		if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		json.NewEncoder(w).Encode(pages[reqBody.Offset])
+	}))
+	defer testServer.Close()
+
+	c := vq.NewClient(vq.ClientConfig{
+		Client:      &http.Client{},
+		ApiUrl:      testServer.URL,
+		GamesPath:   "/Games/records?block_id=games-block",
+		GamesPageID: "games-page",
+	})
+
+	t.Run("GetGames requests the configured games endpoint", func(t *testing.T) {
+		got, err := c.GetGames(context.Background(), 1, "")
+		if err != nil {
+			t.Fatalf("Client.GetGames() error = %v", err)
+		}
+
+		if !reflect.DeepEqual(got, pages[""]) {
+			t.Errorf("Client.GetGames() = %v, want %v", got, pages[""])
+		}
+	})
+
+	t.Run("AllGamesByTeam follows the offset until every page is read", func(t *testing.T) {
+		got, err := c.AllGamesByTeam(context.Background(), "aces")
+		if err != nil {
+			t.Fatalf("Client.AllGamesByTeam() error = %v", err)
+		}
+
+		if len(got) != 2 || got[0].ID != "1" || got[1].ID != "2" {
+			t.Errorf("Client.AllGamesByTeam() = %v, want both pages", got)
+		}
+	})
+
+	t.Run("AllGamesByTeamAndDuty requests the configured games endpoint", func(t *testing.T) {
+		if _, err := c.AllGamesByTeamAndDuty(context.Background(), "aces"); err != nil {
+			t.Errorf("Client.AllGamesByTeamAndDuty() error = %v", err)
+		}
+	})
+}
+
+func TestClient_FilterFormulas(t *testing.T) {
+	var gotFilter string
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var reqBody vq.GetGamesRequestBody
+		if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		gotFilter = reqBody.FilterByFormula
+		w.Write([]byte(`{"records": []}`))
+	}))
+	defer testServer.Close()
+
+	c := vq.NewClient(vq.ClientConfig{
+		Client:      &http.Client{},
+		ApiUrl:      testServer.URL,
+		GamesPath:   "/Games/records?block_id=games-block",
+		GamesPageID: "games-page",
+		Division:    "MD",
+	})
+
+	tests := []struct {
+		name    string
+		request func() error
+		want    string
+	}{
+		{
+			name: "games by team escape the team name",
+			request: func() error {
+				_, err := c.GetGamesByTeam(context.Background(), 1, "", `Aces") , TRUE(`)
+				return err
+			},
+			want: `SEARCH(LOWER("Aces\") , TRUE("), LOWER(ARRAYJOIN({Display_Identifier})))`,
+		},
+		{
+			name: "games by duty team escape the team name",
+			request: func() error {
+				_, err := c.GetGamesByTeamAndDuty(context.Background(), 1, "", `APG"`)
+				return err
+			},
+			want: `SEARCH(LOWER("APG\""), LOWER(ARRAYJOIN({LinkTmD})))`,
+		},
+		{
+			name: "games between teams match either side of the draw",
+			request: func() error {
+				_, err := c.GetGamesBetween(context.Background(), 1, "", "Aces", "APG")
+				return err
+			},
+			want: `OR(AND(SEARCH(LOWER("Aces"), LOWER(ARRAYJOIN({LinkTmA}))), SEARCH(LOWER("APG"), LOWER(ARRAYJOIN({LinkTmB})))), AND(SEARCH(LOWER("APG"), LOWER(ARRAYJOIN({LinkTmA}))), SEARCH(LOWER("Aces"), LOWER(ARRAYJOIN({LinkTmB})))))`,
+		},
+		{
+			name: "ladder filters by division",
+			request: func() error {
+				_, err := c.GetLadderPage(context.Background(), 1, "")
+				return err
+			},
+			want: `(LOWER("MD") = LOWER(ARRAYJOIN({Division})))`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.request(); err != nil {
+				t.Fatalf("request error = %v", err)
+			}
+
+			if gotFilter != tt.want {
+				t.Errorf("filter_by_formula = %v, want %v", gotFilter, tt.want)
 			}
 		})
 	}
 }
+
+func TestClient_GetDivisionLadder(t *testing.T) {
+	s := vqtest.NewServer(t)
+	c := s.NewClient()
+
+	tests := []struct {
+		name      string
+		division  string
+		wantTeams []string
+	}{
+		{name: "one division", division: "WD", wantTeams: []string{"Setters", "Block Party"}},
+		{name: "every division", division: "", wantTeams: []string{"Net Results", "Dig Deep", "Blockers", "Spike Force", "APG", "Aces", "Setters", "Block Party"}},
+		{name: "unknown division", division: "XD", wantTeams: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ladder, err := c.GetDivisionLadder(context.Background(), tt.division)
+			if err != nil {
+				t.Fatalf("Client.GetDivisionLadder() error = %v", err)
+			}
+
+			var got []string
+			for _, record := range ladder.Records {
+				got = append(got, record.Fields.TeamName)
+			}
+
+			if !reflect.DeepEqual(got, tt.wantTeams) {
+				t.Errorf("Client.GetDivisionLadder() teams = %v, want %v", got, tt.wantTeams)
+			}
+		})
+	}
+
+	divisions, err := c.Divisions(context.Background())
+	if err != nil {
+		t.Fatalf("Client.Divisions() error = %v", err)
+	}
+
+	if want := []string{"MD", "WD"}; !reflect.DeepEqual(divisions, want) {
+		t.Errorf("Client.Divisions() = %v, want %v", divisions, want)
+	}
+}
+
+func TestClient_NoGames(t *testing.T) {
+	var requests atomic.Int32
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Write([]byte(`{"records": []}`))
+	}))
+	defer testServer.Close()
+
+	// the default competition has no games ids, so the client has no games endpoint.
+	c := vq.NewClient(vq.ClientConfig{Client: &http.Client{}, ApiUrl: testServer.URL})
+	if c.HasGames() {
+		t.Fatalf("Client.HasGames() = true, want false without a games endpoint")
+	}
+
+	if _, err := c.AllGames(context.Background()); !errors.Is(err, vq.ErrNoGames) {
+		t.Errorf("Client.AllGames() error = %v, want %v", err, vq.ErrNoGames)
+	}
+
+	if got := requests.Load(); got != 0 {
+		t.Errorf("Client.AllGames() made %d requests, want none", got)
+	}
+
+	// the ladder doesn't need the games endpoint.
+	if _, err := c.GetLadder(context.Background()); err != nil {
+		t.Errorf("Client.GetLadder() error = %v", err)
+	}
+}
@@ -0,0 +1,312 @@
+package vq
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Export formats registered by default.
+const (
+	FormatCSV      = "csv"
+	FormatJSON     = "json"
+	FormatMarkdown = "markdown"
+	FormatHTML     = "html"
+)
+
+// ErrUnknownFormat is returned when no renderer is registered for a format.
+var ErrUnknownFormat = errors.New("unknown export format")
+
+// Renderer writes the ladder or a list of fixtures in a file format.
+type Renderer interface {
+	RenderLadder(w io.Writer, ladder GetLadderResponseBody) error
+	RenderFixtures(w io.Writer, games []GameRecord) error
+	// Extension is the file extension without the dot.
+	Extension() string
+	ContentType() string
+}
+
+var (
+	renderersMu sync.RWMutex
+	renderers   = map[string]Renderer{
+		FormatCSV:      CSVRenderer{},
+		FormatJSON:     JSONRenderer{},
+		FormatMarkdown: MarkdownRenderer{},
+		FormatHTML:     HTMLRenderer{},
+	}
+)
+
+// RegisterRenderer makes a renderer available for the format, replacing any renderer
+// already registered for it. Formats are case insensitive.
+func RegisterRenderer(format string, r Renderer) {
+	renderersMu.Lock()
+	defer renderersMu.Unlock()
+
+	renderers[strings.ToLower(format)] = r
+}
+
+// LookupRenderer returns the renderer registered for the format.
+func LookupRenderer(format string) (Renderer, error) {
+	renderersMu.RLock()
+	defer renderersMu.RUnlock()
+
+	r, ok := renderers[strings.ToLower(strings.TrimSpace(format))]
+	if !ok {
+		return nil, fmt.Errorf("LookupRenderer() %q: %w", format, ErrUnknownFormat)
+	}
+
+	return r, nil
+}
+
+// Formats returns the registered formats in alphabetical order.
+func Formats() []string {
+	renderersMu.RLock()
+	defer renderersMu.RUnlock()
+
+	formats := make([]string, 0, len(renderers))
+	for format := range renderers {
+		formats = append(formats, format)
+	}
+	sort.Strings(formats)
+
+	return formats
+}
+
+// table is the ladder or fixtures laid out as rows of cells, shared by the tabular formats.
+type table struct {
+	title  string
+	header []string
+	rows   [][]string
+}
+
+func ladderTable(ladder GetLadderResponseBody) (table, error) {
+	standings, err := ladder.Standings()
+	if err != nil {
+		return table{}, fmt.Errorf("ladderTable() unable to parse ladder: %w", err)
+	}
+
+	t := table{
+		title:  "Ladder",
+		header: []string{"Rank", "Team", "Played", "Won", "Lost", "Drawn", "Sets For", "Sets Against", "Points For", "Points Against", "Competition Points"},
+	}
+
+	if len(standings) > 0 && standings[0].Division != "" {
+		t.title = "Ladder " + standings[0].Division
+	}
+
+	for _, s := range standings {
+		t.rows = append(t.rows, []string{
+			strconv.Itoa(s.Rank),
+			s.Team,
+			strconv.Itoa(s.Matches.Played),
+			strconv.Itoa(s.Matches.Won),
+			strconv.Itoa(s.Matches.Lost),
+			strconv.Itoa(s.Matches.Drawn),
+			strconv.Itoa(s.Sets.For),
+			strconv.Itoa(s.Sets.Against),
+			strconv.Itoa(s.Points.For),
+			strconv.Itoa(s.Points.Against),
+			strconv.FormatFloat(s.CompetitionPoints, 'f', -1, 64),
+		})
+	}
+
+	return t, nil
+}
+
+func fixturesTable(games []GameRecord) table {
+	t := table{
+		title:  "Fixtures",
+		header: []string{"Round", "Date", "Time", "Venue", "Court", "Team A", "Team B", "Duty", "Sets A", "Sets B", "Points A", "Points B"},
+	}
+
+	for _, g := range games {
+		f := g.Fields
+		t.rows = append(t.rows, []string{
+			f.Round, f.GameDay, f.GameTime, f.Venue, f.Court, f.TeamA, f.TeamB, f.DutyTeam,
+			f.SetsWonA, f.SetsWonB, f.PointsWonA, f.PointsWonB,
+		})
+	}
+
+	return t
+}
+
+// CSVRenderer writes a header row followed by one row per team or game.
+type CSVRenderer struct{}
+
+func (CSVRenderer) Extension() string   { return "csv" }
+func (CSVRenderer) ContentType() string { return "text/csv" }
+
+func (r CSVRenderer) RenderLadder(w io.Writer, ladder GetLadderResponseBody) error {
+	t, err := ladderTable(ladder)
+	if err != nil {
+		return fmt.Errorf("RenderLadder() csv: %w", err)
+	}
+
+	return r.render(w, t)
+}
+
+func (r CSVRenderer) RenderFixtures(w io.Writer, games []GameRecord) error {
+	return r.render(w, fixturesTable(games))
+}
+
+func (CSVRenderer) render(w io.Writer, t table) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(t.header); err != nil {
+		return fmt.Errorf("render() csv: %w", err)
+	}
+
+	if err := cw.WriteAll(t.rows); err != nil {
+		return fmt.Errorf("render() csv: %w", err)
+	}
+
+	return nil
+}
+
+// JSONRenderer writes the parsed standings or the game records as an indented array.
+type JSONRenderer struct{}
+
+func (JSONRenderer) Extension() string   { return "json" }
+func (JSONRenderer) ContentType() string { return "application/json" }
+
+func (r JSONRenderer) RenderLadder(w io.Writer, ladder GetLadderResponseBody) error {
+	standings, err := ladder.Standings()
+	if err != nil {
+		return fmt.Errorf("RenderLadder() json unable to parse ladder: %w", err)
+	}
+
+	return r.render(w, standings)
+}
+
+func (r JSONRenderer) RenderFixtures(w io.Writer, games []GameRecord) error {
+	if games == nil {
+		games = []GameRecord{}
+	}
+
+	return r.render(w, games)
+}
+
+func (JSONRenderer) render(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return fmt.Errorf("render() json: %w", err)
+	}
+
+	return nil
+}
+
+// MarkdownRenderer writes a github flavoured markdown table under a heading.
+type MarkdownRenderer struct{}
+
+func (MarkdownRenderer) Extension() string   { return "md" }
+func (MarkdownRenderer) ContentType() string { return "text/markdown" }
+
+func (r MarkdownRenderer) RenderLadder(w io.Writer, ladder GetLadderResponseBody) error {
+	t, err := ladderTable(ladder)
+	if err != nil {
+		return fmt.Errorf("RenderLadder() markdown: %w", err)
+	}
+
+	return r.render(w, t)
+}
+
+func (r MarkdownRenderer) RenderFixtures(w io.Writer, games []GameRecord) error {
+	return r.render(w, fixturesTable(games))
+}
+
+func (MarkdownRenderer) render(w io.Writer, t table) error {
+	sb := strings.Builder{}
+	sb.WriteString(fmt.Sprintf("# %s\n\n", t.title))
+
+	row := func(cells []string) {
+		escaped := make([]string, len(cells))
+		for i, cell := range cells {
+			escaped[i] = strings.ReplaceAll(cell, "|", `\|`)
+		}
+		sb.WriteString("| " + strings.Join(escaped, " | ") + " |\n")
+	}
+
+	row(t.header)
+	separator := make([]string, len(t.header))
+	for i := range separator {
+		separator[i] = "---"
+	}
+	row(separator)
+
+	for _, cells := range t.rows {
+		row(cells)
+	}
+
+	if _, err := io.WriteString(w, sb.String()); err != nil {
+		return fmt.Errorf("render() markdown: %w", err)
+	}
+
+	return nil
+}
+
+// HTMLRenderer writes a standalone html page holding the table.
+type HTMLRenderer struct{}
+
+func (HTMLRenderer) Extension() string   { return "html" }
+func (HTMLRenderer) ContentType() string { return "text/html; charset=utf-8" }
+
+func (r HTMLRenderer) RenderLadder(w io.Writer, ladder GetLadderResponseBody) error {
+	t, err := ladderTable(ladder)
+	if err != nil {
+		return fmt.Errorf("RenderLadder() html: %w", err)
+	}
+
+	return r.render(w, t)
+}
+
+func (r HTMLRenderer) RenderFixtures(w io.Writer, games []GameRecord) error {
+	return r.render(w, fixturesTable(games))
+}
+
+var htmlPage = template.Must(template.New("page").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 0.3em 0.6em; text-align: left; }
+th { background: #f0f0f0; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<table>
+<thead>
+<tr>{{range .Header}}<th>{{.}}</th>{{end}}</tr>
+</thead>
+<tbody>
+{{- range .Rows}}
+<tr>{{range .}}<td>{{.}}</td>{{end}}</tr>
+{{- end}}
+</tbody>
+</table>
+</body>
+</html>
+`))
+
+func (HTMLRenderer) render(w io.Writer, t table) error {
+	err := htmlPage.Execute(w, struct {
+		Title  string
+		Header []string
+		Rows   [][]string
+	}{t.title, t.header, t.rows})
+	if err != nil {
+		return fmt.Errorf("render() html: %w", err)
+	}
+
+	return nil
+}
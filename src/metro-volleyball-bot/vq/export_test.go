@@ -0,0 +1,191 @@
+package vq_test
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/brewinski/home-lab-server/src/metro-volleyball-bot/vq"
+)
+
+func exportLadder() vq.GetLadderResponseBody {
+	return vq.GetLadderResponseBody{Records: []vq.LadderRecord{
+		{ID: "rec1", Fields: vq.LadderFields{Rank: "1", TeamName: "Aces", Division: "MD", MatchesPlayed: "3", MatchesWon: "3", MatchesLost: "0", SetsFor: "9", SetsAgainst: "0", PointsFor: "225", PointsAgainst: "150", CompetitionPoints: "18"}},
+		{ID: "rec2", Fields: vq.LadderFields{Rank: "2", TeamName: "Block | Party", Division: "MD", MatchesPlayed: "3", MatchesWon: "1", MatchesLost: "2", SetsFor: "4", SetsAgainst: "5", PointsFor: "190", PointsAgainst: "200", CompetitionPoints: "7.5"}},
+	}}
+}
+
+func exportGames() []vq.GameRecord {
+	return []vq.GameRecord{
+		{ID: "g1", Fields: vq.GameFields{Round: "1", GameDay: "2/5/2024", GameTime: "6:30pm", Venue: "Hall", Court: "Court 1", TeamA: "Aces", TeamB: "<Dig> Deep", DutyTeam: "Setters", SetsWonA: "3", SetsWonB: "0"}},
+		{ID: "g2", Fields: vq.GameFields{Round: "2", GameDay: "9/5/2024", GameTime: "7:45pm", Venue: "Hall", Court: "Court 2", TeamA: "Setters", TeamB: "Aces"}},
+	}
+}
+
+func TestLookupRenderer(t *testing.T) {
+	tests := []struct {
+		format        string
+		wantExtension string
+		wantErr       error
+	}{
+		{format: "csv", wantExtension: "csv"},
+		{format: "JSON", wantExtension: "json"},
+		{format: " markdown ", wantExtension: "md"},
+		{format: "html", wantExtension: "html"},
+		{format: "xlsx", wantErr: vq.ErrUnknownFormat},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			got, err := vq.LookupRenderer(tt.format)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("LookupRenderer() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if err == nil && got.Extension() != tt.wantExtension {
+				t.Errorf("LookupRenderer() extension = %v, want %v", got.Extension(), tt.wantExtension)
+			}
+		})
+	}
+}
+
+type upperRenderer struct{ vq.CSVRenderer }
+
+func (upperRenderer) Extension() string { return "CSV" }
+
+func TestRegisterRenderer(t *testing.T) {
+	vq.RegisterRenderer("Upper", upperRenderer{})
+
+	got, err := vq.LookupRenderer("upper")
+	if err != nil {
+		t.Fatalf("LookupRenderer() error = %v", err)
+	}
+
+	if got.Extension() != "CSV" {
+		t.Errorf("LookupRenderer() extension = %v, want CSV", got.Extension())
+	}
+
+	want := []string{"csv", "html", "json", "markdown", "upper"}
+	if formats := vq.Formats(); !reflect.DeepEqual(formats, want) {
+		t.Errorf("Formats() = %v, want %v", formats, want)
+	}
+}
+
+func TestCSVRenderer(t *testing.T) {
+	buf := bytes.Buffer{}
+	if err := (vq.CSVRenderer{}).RenderLadder(&buf, exportLadder()); err != nil {
+		t.Fatalf("RenderLadder() error = %v", err)
+	}
+
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("RenderLadder() wrote invalid csv: %v", err)
+	}
+
+	want := [][]string{
+		{"Rank", "Team", "Played", "Won", "Lost", "Drawn", "Sets For", "Sets Against", "Points For", "Points Against", "Competition Points"},
+		{"1", "Aces", "3", "3", "0", "0", "9", "0", "225", "150", "18"},
+		{"2", "Block | Party", "3", "1", "2", "0", "4", "5", "190", "200", "7.5"},
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("RenderLadder() = %v, want %v", rows, want)
+	}
+
+	buf.Reset()
+	if err := (vq.CSVRenderer{}).RenderFixtures(&buf, exportGames()); err != nil {
+		t.Fatalf("RenderFixtures() error = %v", err)
+	}
+
+	rows, err = csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("RenderFixtures() wrote invalid csv: %v", err)
+	}
+
+	wantGame := []string{"1", "2/5/2024", "6:30pm", "Hall", "Court 1", "Aces", "<Dig> Deep", "Setters", "3", "0", "", ""}
+	if len(rows) != 3 || !reflect.DeepEqual(rows[1], wantGame) {
+		t.Errorf("RenderFixtures() = %v, want header and 2 games starting with %v", rows, wantGame)
+	}
+}
+
+func TestJSONRenderer(t *testing.T) {
+	buf := bytes.Buffer{}
+	if err := (vq.JSONRenderer{}).RenderLadder(&buf, exportLadder()); err != nil {
+		t.Fatalf("RenderLadder() error = %v", err)
+	}
+
+	var standings []vq.Standing
+	if err := json.Unmarshal(buf.Bytes(), &standings); err != nil {
+		t.Fatalf("RenderLadder() wrote invalid json: %v", err)
+	}
+
+	want, _ := exportLadder().Standings()
+	if !reflect.DeepEqual(standings, want) {
+		t.Errorf("RenderLadder() = %v, want %v", standings, want)
+	}
+
+	buf.Reset()
+	if err := (vq.JSONRenderer{}).RenderFixtures(&buf, nil); err != nil {
+		t.Fatalf("RenderFixtures() error = %v", err)
+	}
+
+	if got := strings.TrimSpace(buf.String()); got != "[]" {
+		t.Errorf("RenderFixtures() = %v, want an empty array", got)
+	}
+}
+
+func TestMarkdownRenderer(t *testing.T) {
+	buf := bytes.Buffer{}
+	if err := (vq.MarkdownRenderer{}).RenderLadder(&buf, exportLadder()); err != nil {
+		t.Fatalf("RenderLadder() error = %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	want := []string{
+		"# Ladder MD",
+		"",
+		"| Rank | Team | Played | Won | Lost | Drawn | Sets For | Sets Against | Points For | Points Against | Competition Points |",
+		"| --- | --- | --- | --- | --- | --- | --- | --- | --- | --- | --- |",
+		"| 1 | Aces | 3 | 3 | 0 | 0 | 9 | 0 | 225 | 150 | 18 |",
+		`| 2 | Block \| Party | 3 | 1 | 2 | 0 | 4 | 5 | 190 | 200 | 7.5 |`,
+	}
+	if !reflect.DeepEqual(lines, want) {
+		t.Errorf("RenderLadder() = %q, want %q", lines, want)
+	}
+}
+
+func TestHTMLRenderer(t *testing.T) {
+	buf := bytes.Buffer{}
+	if err := (vq.HTMLRenderer{}).RenderFixtures(&buf, exportGames()); err != nil {
+		t.Fatalf("RenderFixtures() error = %v", err)
+	}
+
+	page := buf.String()
+	for _, want := range []string{"<!DOCTYPE html>", "<title>Fixtures</title>", "<th>Team A</th>", "<td>&lt;Dig&gt; Deep</td>", "</html>"} {
+		if !strings.Contains(page, want) {
+			t.Errorf("RenderFixtures() = %v, want it to contain %q", page, want)
+		}
+	}
+
+	if got := strings.Count(page, "<tr>"); got != 3 {
+		t.Errorf("RenderFixtures() rows = %d, want 3", got)
+	}
+}
+
+func TestRenderLadder_InvalidLadder(t *testing.T) {
+	ladder := vq.GetLadderResponseBody{Records: []vq.LadderRecord{{ID: "rec1", Fields: vq.LadderFields{Rank: "first"}}}}
+
+	for _, format := range []string{vq.FormatCSV, vq.FormatJSON, vq.FormatMarkdown, vq.FormatHTML} {
+		r, err := vq.LookupRenderer(format)
+		if err != nil {
+			t.Fatalf("LookupRenderer() error = %v", err)
+		}
+
+		var fieldErr *vq.FieldError
+		if err := r.RenderLadder(&bytes.Buffer{}, ladder); !errors.As(err, &fieldErr) {
+			t.Errorf("%s RenderLadder() error = %v, want a FieldError", format, err)
+		}
+	}
+}
@@ -0,0 +1,237 @@
+package vq
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"unicode/utf8"
+)
+
+// Fairness measures, the counts compared between teams.
+const (
+	MeasureEarly  = "6:30"
+	MeasureLate   = "9:00"
+	MeasureDuties = "duties"
+)
+
+// Load is how many games a team has been given in each timeslot and how many duties it
+// has done.
+type Load struct {
+	Team   string
+	Early  int
+	Middle int
+	Late   int
+	Duties int
+}
+
+// measure returns the count for one of the fairness measures.
+func (l Load) measure(measure string) int {
+	switch measure {
+	case MeasureEarly:
+		return l.Early
+	case MeasureLate:
+		return l.Late
+	case MeasureDuties:
+		return l.Duties
+	default:
+		return 0
+	}
+}
+
+// Load reads the timeslot and duty counts from the ladder record. The slot counts fall
+// back to the team A and team B counts when the total isn't provided.
+func (r LadderRecord) Load() (Load, error) {
+	p := fieldParser{recordID: r.ID}
+	f := r.Fields
+
+	team := f.TeamName
+	if team == "" {
+		team = f.TeamNameLookup
+	}
+
+	slot := func(count, countField, teamA, teamAField, teamB, teamBField string) int {
+		if strings.TrimSpace(count) != "" {
+			return p.int(countField, count)
+		}
+
+		return p.int(teamAField, teamA) + p.int(teamBField, teamB)
+	}
+
+	load := Load{
+		Team:   team,
+		Early:  slot(f.Count630, "Count-6:30", f.TmA630, "TmA-6:30", f.TmB630, "TmB-6:30"),
+		Middle: slot(f.Count745, "Count-7:45pm", f.TmA745, "TmA-7:45", f.TmB745, "TmB-7:45"),
+		Late:   slot(f.Count900, "Count-9:00", f.TmA900, "TmA-9:00", f.TmB900, "TmB-9:00"),
+		Duties: p.int("DutyCount", f.DutyCount),
+	}
+
+	if err := p.err(); err != nil {
+		return Load{}, fmt.Errorf("Load() invalid ladder record: %w", err)
+	}
+
+	return load, nil
+}
+
+// Loads reads the counts of every team on the ladder, keeping the ladder order.
+func (ladder GetLadderResponseBody) Loads() ([]Load, error) {
+	loads := make([]Load, 0, len(ladder.Records))
+	for _, record := range ladder.Records {
+		load, err := record.Load()
+		if err != nil {
+			return nil, fmt.Errorf("Loads() unable to read ladder: %w", err)
+		}
+		loads = append(loads, load)
+	}
+
+	return loads, nil
+}
+
+// Stat is the mean and population standard deviation of a measure across the teams.
+type Stat struct {
+	Mean   float64
+	StdDev float64
+}
+
+// Outlier is a team more than one standard deviation from the mean of a measure.
+type Outlier struct {
+	Team    string
+	Measure string
+	Value   int
+	Stat    Stat
+}
+
+// Above reports whether the team has more than the mean.
+func (o Outlier) Above() bool {
+	return float64(o.Value) > o.Stat.Mean
+}
+
+// String formats the outlier, e.g. "Aces 6:30: 5 (mean 1.8, sd 1.5)".
+func (o Outlier) String() string {
+	return fmt.Sprintf("%s %s: %d (mean %.1f, sd %.1f)", o.Team, o.Measure, o.Value, o.Stat.Mean, o.Stat.StdDev)
+}
+
+// FairnessReport compares the early slots, late slots and duties given to each team.
+type FairnessReport struct {
+	Loads []Load
+	// Stats are keyed by measure.
+	Stats    map[string]Stat
+	Outliers []Outlier
+}
+
+// Fairness flags teams that are more than one standard deviation from the mean number of
+// early slots, late slots or duties. Nobody is flagged for a measure every team shares.
+func Fairness(loads []Load) FairnessReport {
+	report := FairnessReport{Loads: loads, Stats: map[string]Stat{}}
+
+	for _, measure := range []string{MeasureEarly, MeasureLate, MeasureDuties} {
+		values := make([]float64, len(loads))
+		for i, load := range loads {
+			values[i] = float64(load.measure(measure))
+		}
+
+		stat := newStat(values)
+		report.Stats[measure] = stat
+
+		if stat.StdDev == 0 {
+			continue
+		}
+
+		for _, load := range loads {
+			if math.Abs(float64(load.measure(measure))-stat.Mean) > stat.StdDev {
+				report.Outliers = append(report.Outliers, Outlier{Team: load.Team, Measure: measure, Value: load.measure(measure), Stat: stat})
+			}
+		}
+	}
+
+	return report
+}
+
+func newStat(values []float64) Stat {
+	if len(values) == 0 {
+		return Stat{}
+	}
+
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+
+	variance := 0.0
+	for _, v := range values {
+		variance += (v - mean) * (v - mean)
+	}
+
+	return Stat{Mean: mean, StdDev: math.Sqrt(variance / float64(len(values)))}
+}
+
+// flag marks a count that's above (+) or below (-) the mean by more than one standard
+// deviation.
+func (f FairnessReport) flag(team, measure string) string {
+	for _, outlier := range f.Outliers {
+		if outlier.Team != team || outlier.Measure != measure {
+			continue
+		}
+
+		if outlier.Above() {
+			return "+"
+		}
+		return "-"
+	}
+
+	return " "
+}
+
+// String formats the report as a discord message, a table of every team's counts followed
+// by the flagged teams.
+func (f FairnessReport) String() string {
+	if len(f.Loads) == 0 {
+		return "no teams on the ladder yet"
+	}
+
+	teamWidth := len("Team")
+	for _, load := range f.Loads {
+		teamWidth = max(teamWidth, utf8.RuneCountInString(load.Team))
+	}
+
+	sb := strings.Builder{}
+	sb.WriteString("Timeslots and duties, + and - mark teams more than one standard deviation from the mean:\n```\n")
+	sb.WriteString(fmt.Sprintf("%s %4s  %4s  %4s  %4s\n", pad("Team", teamWidth), "6:30", "7:45", "9:00", "Duty"))
+
+	for _, load := range f.Loads {
+		row := fmt.Sprintf("%s %4d%s %4d  %4d%s %4d%s",
+			pad(load.Team, teamWidth),
+			load.Early, f.flag(load.Team, MeasureEarly),
+			load.Middle,
+			load.Late, f.flag(load.Team, MeasureLate),
+			load.Duties, f.flag(load.Team, MeasureDuties),
+		)
+		sb.WriteString(strings.TrimRight(row, " ") + "\n")
+	}
+
+	sb.WriteString(fmt.Sprintf("%s %4.1f  %4s  %4.1f  %4.1f\n", pad("mean", teamWidth),
+		f.Stats[MeasureEarly].Mean, "", f.Stats[MeasureLate].Mean, f.Stats[MeasureDuties].Mean))
+	sb.WriteString("```")
+
+	if len(f.Outliers) == 0 {
+		sb.WriteString("\nno teams flagged")
+		return sb.String()
+	}
+
+	sb.WriteString("\nflagged:")
+	for _, outlier := range f.Outliers {
+		direction := "fewer"
+		if outlier.Above() {
+			direction = "more"
+		}
+		sb.WriteString(fmt.Sprintf("\n\t%s, %s than most", outlier, direction))
+	}
+
+	return sb.String()
+}
+
+// pad fills s with spaces to width characters, fmt pads by bytes which misaligns names
+// with accents.
+func pad(s string, width int) string {
+	return s + strings.Repeat(" ", max(0, width-utf8.RuneCountInString(s)))
+}
@@ -0,0 +1,113 @@
+package vq_test
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/brewinski/home-lab-server/src/metro-volleyball-bot/vq"
+)
+
+func TestLadderRecord_Load(t *testing.T) {
+	tests := []struct {
+		name    string
+		record  vq.LadderRecord
+		want    vq.Load
+		wantErr bool
+	}{
+		{
+			name:   "slot totals",
+			record: vq.LadderRecord{ID: "rec1", Fields: vq.LadderFields{TeamName: "Aces", Count630: "5", Count745: "1", Count900: "0", DutyCount: "4"}},
+			want:   vq.Load{Team: "Aces", Early: 5, Middle: 1, Late: 0, Duties: 4},
+		},
+		{
+			name:   "team a and b counts without a total",
+			record: vq.LadderRecord{ID: "rec1", Fields: vq.LadderFields{TeamName: "Aces", TmA630: "2", TmB630: "1", TmA900: "1"}},
+			want:   vq.Load{Team: "Aces", Early: 3, Late: 1},
+		},
+		{
+			name:    "invalid count",
+			record:  vq.LadderRecord{ID: "rec1", Fields: vq.LadderFields{TeamName: "Aces", DutyCount: "lots"}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.record.Load()
+			var fieldErr *vq.FieldError
+			if tt.wantErr != errors.As(err, &fieldErr) {
+				t.Fatalf("Load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Load() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFairness(t *testing.T) {
+	loads := []vq.Load{
+		{Team: "Nét Résults", Early: 1, Middle: 2, Late: 2, Duties: 2},
+		{Team: "Dig Deep", Early: 1, Middle: 2, Late: 2, Duties: 2},
+		{Team: "Blockers", Early: 1, Middle: 2, Late: 2, Duties: 2},
+		{Team: "Aces", Early: 5, Middle: 0, Late: 0, Duties: 2},
+	}
+
+	report := vq.Fairness(loads)
+
+	if stat := report.Stats[vq.MeasureEarly]; stat.Mean != 2 || math.Abs(stat.StdDev-math.Sqrt(3)) > 1e-9 {
+		t.Errorf("Fairness() early stat = %+v, want mean 2 and sd 1.73", stat)
+	}
+
+	var got []string
+	for _, outlier := range report.Outliers {
+		got = append(got, outlier.String())
+	}
+
+	want := []string{
+		"Aces 6:30: 5 (mean 2.0, sd 1.7)",
+		"Aces 9:00: 0 (mean 1.5, sd 0.9)",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Fairness() outliers = %q, want %q", got, want)
+	}
+
+	if report.Outliers[0].Above() != true || report.Outliers[1].Above() != false {
+		t.Errorf("Fairness() outliers = %+v, want Aces above on early and below on late", report.Outliers)
+	}
+}
+
+func TestFairnessReport_String(t *testing.T) {
+	report := vq.Fairness([]vq.Load{
+		{Team: "Nét Résults", Early: 1, Middle: 2, Late: 2, Duties: 2},
+		{Team: "Dig Deep", Early: 1, Middle: 2, Late: 2, Duties: 2},
+		{Team: "Blockers", Early: 1, Middle: 2, Late: 2, Duties: 2},
+		{Team: "Aces", Early: 5, Middle: 0, Late: 0, Duties: 2},
+	})
+
+	lines := strings.Split(report.String(), "\n")
+	want := []string{
+		"Timeslots and duties, + and - mark teams more than one standard deviation from the mean:",
+		"```",
+		"Team        6:30  7:45  9:00  Duty",
+		"Nét Résults    1     2     2     2",
+		"Dig Deep       1     2     2     2",
+		"Blockers       1     2     2     2",
+		"Aces           5+    0     0-    2",
+		"mean         2.0         1.5   2.0",
+		"```",
+		"flagged:",
+		"\tAces 6:30: 5 (mean 2.0, sd 1.7), more than most",
+		"\tAces 9:00: 0 (mean 1.5, sd 0.9), fewer than most",
+	}
+	if strings.Join(lines, "\n") != strings.Join(want, "\n") {
+		t.Errorf("FairnessReport.String() =\n%s\nwant\n%s", strings.Join(lines, "\n"), strings.Join(want, "\n"))
+	}
+
+	if got := vq.Fairness([]vq.Load{{Team: "Aces"}, {Team: "APG"}}).String(); !strings.HasSuffix(got, "no teams flagged") {
+		t.Errorf("FairnessReport.String() = %v, want no teams flagged when the counts are even", got)
+	}
+}
@@ -0,0 +1,152 @@
+package vq
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FixtureDiff is what changed between two snapshots of the fixtures. Games are matched by
+// their MatchID.
+type FixtureDiff struct {
+	// Changes are the games in both snapshots that were rescheduled or had a team
+	// change, in the order of the new snapshot.
+	Changes []FixtureChange
+	// Added are the games only in the new snapshot.
+	Added []GameRecord
+	// Removed are the games only in the old snapshot.
+	Removed []GameRecord
+}
+
+// FixtureChange is a game before and after it was changed.
+type FixtureChange struct {
+	Old    GameRecord
+	New    GameRecord
+	Fields []FieldChange
+}
+
+// FieldChange is a single field of a game that changed.
+type FieldChange struct {
+	Field string
+	From  string
+	To    string
+}
+
+// String describes the change, e.g. "R3 Aces v APG changed: time 7:45pm → 9:00pm".
+func (c FixtureChange) String() string {
+	changes := make([]string, 0, len(c.Fields))
+	for _, field := range c.Fields {
+		changes = append(changes, fmt.Sprintf("%s %s → %s", field.Field, orNone(field.From), orNone(field.To)))
+	}
+
+	return fmt.Sprintf("%s changed: %s", c.Old.Title(), strings.Join(changes, ", "))
+}
+
+// Empty reports whether the fixtures are the same.
+func (d FixtureDiff) Empty() bool {
+	return len(d.Changes) == 0 && len(d.Added) == 0 && len(d.Removed) == 0
+}
+
+// String describes the diff one line per game, ready to be posted as an alert.
+func (d FixtureDiff) String() string {
+	var lines []string
+
+	for _, change := range d.Changes {
+		lines = append(lines, change.String())
+	}
+
+	for _, game := range d.Added {
+		lines = append(lines, "New game: "+game.Summary())
+	}
+
+	for _, game := range d.Removed {
+		lines = append(lines, "Game removed: "+game.Summary())
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// Title names the round and teams, e.g. "R3 Aces v APG".
+func (g GameRecord) Title() string {
+	return fmt.Sprintf("R%s %s v %s", g.Fields.Round, g.Fields.TeamA, g.Fields.TeamB)
+}
+
+// Summary describes when and where the game is played, e.g.
+// "R3 Aces v APG, 19/2/2024 7:45pm at Auchenflower Stadium Court 1".
+func (g GameRecord) Summary() string {
+	return fmt.Sprintf("%s, %s %s at %s %s", g.Title(), g.Fields.GameDay, g.Fields.GameTime, g.Fields.Venue, g.Fields.Court)
+}
+
+// DiffFixtures compares two snapshots of the fixtures by MatchID, falling back to the
+// record id for games without one. The day, time, venue, court and teams are compared.
+func DiffFixtures(old, new []GameRecord) FixtureDiff {
+	previous := make(map[string]GameRecord, len(old))
+	for _, game := range old {
+		previous[fixtureKey(game)] = game
+	}
+
+	diff := FixtureDiff{}
+	seen := make(map[string]bool, len(new))
+
+	for _, game := range new {
+		key := fixtureKey(game)
+		seen[key] = true
+
+		before, ok := previous[key]
+		if !ok {
+			diff.Added = append(diff.Added, game)
+			continue
+		}
+
+		if fields := diffGameFields(before.Fields, game.Fields); len(fields) > 0 {
+			diff.Changes = append(diff.Changes, FixtureChange{Old: before, New: game, Fields: fields})
+		}
+	}
+
+	for _, game := range old {
+		if !seen[fixtureKey(game)] {
+			diff.Removed = append(diff.Removed, game)
+		}
+	}
+
+	return diff
+}
+
+func diffGameFields(old, new GameFields) []FieldChange {
+	compared := []struct {
+		field    string
+		old, new string
+	}{
+		{"date", old.GameDay, new.GameDay},
+		{"time", old.GameTime, new.GameTime},
+		{"venue", old.Venue, new.Venue},
+		{"court", old.Court, new.Court},
+		{"team a", old.TeamA, new.TeamA},
+		{"team b", old.TeamB, new.TeamB},
+		{"duty", old.DutyTeam, new.DutyTeam},
+	}
+
+	var changes []FieldChange
+	for _, c := range compared {
+		if strings.TrimSpace(c.old) != strings.TrimSpace(c.new) {
+			changes = append(changes, FieldChange{Field: c.field, From: c.old, To: c.new})
+		}
+	}
+
+	return changes
+}
+
+func fixtureKey(game GameRecord) string {
+	if game.Fields.MatchID != "" {
+		return "match:" + game.Fields.MatchID
+	}
+
+	return "record:" + game.ID
+}
+
+func orNone(value string) string {
+	if strings.TrimSpace(value) == "" {
+		return "none"
+	}
+
+	return value
+}
@@ -0,0 +1,131 @@
+package vq_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/brewinski/home-lab-server/src/metro-volleyball-bot/vq"
+	"github.com/brewinski/home-lab-server/src/metro-volleyball-bot/vq/vqtest"
+)
+
+func TestDiffFixtures(t *testing.T) {
+	game := vq.GameRecord{
+		ID: "rec1",
+		Fields: vq.GameFields{
+			TeamA:    "Aces",
+			TeamB:    "APG",
+			DutyTeam: "Blockers",
+			GameTime: "7:45pm",
+			GameDay:  "19/2/2024",
+			Round:    "3",
+			Venue:    "Auchenflower Stadium",
+			Court:    "Court 1",
+			MatchID:  "MD-107",
+		},
+	}
+
+	moved := game
+	moved.Fields.GameTime = "9:00pm"
+	moved.Fields.Court = "Court 2"
+
+	// the same match can come back with a different record id.
+	renumbered := game
+	renumbered.ID = "rec2"
+
+	other := game
+	other.ID = "rec3"
+	other.Fields.MatchID = "MD-108"
+	other.Fields.TeamB = "Dig Deep"
+
+	tests := []struct {
+		name      string
+		old       []vq.GameRecord
+		new       []vq.GameRecord
+		want      vq.FixtureDiff
+		wantLines string
+	}{
+		{
+			name: "no changes",
+			old:  []vq.GameRecord{game},
+			new:  []vq.GameRecord{renumbered},
+			want: vq.FixtureDiff{},
+		},
+		{
+			name: "game rescheduled",
+			old:  []vq.GameRecord{game, other},
+			new:  []vq.GameRecord{moved, other},
+			want: vq.FixtureDiff{
+				Changes: []vq.FixtureChange{{
+					Old: game,
+					New: moved,
+					Fields: []vq.FieldChange{
+						{Field: "time", From: "7:45pm", To: "9:00pm"},
+						{Field: "court", From: "Court 1", To: "Court 2"},
+					},
+				}},
+			},
+			wantLines: "R3 Aces v APG changed: time 7:45pm → 9:00pm, court Court 1 → Court 2",
+		},
+		{
+			name: "games added and removed",
+			old:  []vq.GameRecord{game},
+			new:  []vq.GameRecord{other},
+			want: vq.FixtureDiff{
+				Added:   []vq.GameRecord{other},
+				Removed: []vq.GameRecord{game},
+			},
+			wantLines: "New game: R3 Aces v Dig Deep, 19/2/2024 7:45pm at Auchenflower Stadium Court 1\n" +
+				"Game removed: R3 Aces v APG, 19/2/2024 7:45pm at Auchenflower Stadium Court 1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := vq.DiffFixtures(tt.old, tt.new)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DiffFixtures() = %+v, want %+v", got, tt.want)
+			}
+
+			if got.Empty() != tt.want.Empty() {
+				t.Errorf("FixtureDiff.Empty() = %v, want %v", got.Empty(), tt.want.Empty())
+			}
+
+			if lines := got.String(); lines != tt.wantLines {
+				t.Errorf("FixtureDiff.String() = %q, want %q", lines, tt.wantLines)
+			}
+		})
+	}
+}
+
+func TestDiffFixtures_Server(t *testing.T) {
+	s := vqtest.NewServer(t)
+	client := s.NewClient()
+
+	old, err := client.AllGamesByTeam(context.Background(), "aces")
+	if err != nil {
+		t.Fatalf("Client.AllGamesByTeam() error = %v", err)
+	}
+
+	games := vqtest.GamesFixture(t)
+	for i := range games {
+		if games[i].Fields.MatchID == old[0].Fields.MatchID {
+			games[i].Fields.Venue = "Hills Sports Centre"
+		}
+	}
+	s.SetGames(games)
+
+	new, err := client.AllGamesByTeam(context.Background(), "aces")
+	if err != nil {
+		t.Fatalf("Client.AllGamesByTeam() error = %v", err)
+	}
+
+	diff := vq.DiffFixtures(old, new)
+	if len(diff.Changes) != 1 || len(diff.Added) != 0 || len(diff.Removed) != 0 {
+		t.Fatalf("DiffFixtures() = %+v, want a single changed game", diff)
+	}
+
+	want := []vq.FieldChange{{Field: "venue", From: "Auchenflower Stadium", To: "Hills Sports Centre"}}
+	if !reflect.DeepEqual(diff.Changes[0].Fields, want) {
+		t.Errorf("DiffFixtures() fields = %+v, want %+v", diff.Changes[0].Fields, want)
+	}
+}
@@ -0,0 +1,167 @@
+// Package formula builds airtable filterByFormula expressions for the softr airtable
+// proxy. Values are escaped as they're added so user input, like a team name, can't break
+// out of a string literal and change the meaning of a query.
+package formula
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Expr is a formula expression. Build one with the functions in this package rather than
+// by hand so values are escaped.
+type Expr string
+
+// String returns the formula text.
+func (e Expr) String() string {
+	return string(e)
+}
+
+// Field references a field by name, e.g. {Division}.
+func Field(name string) Expr {
+	escaped := strings.NewReplacer(`\`, `\\`, `}`, `\}`).Replace(name)
+	return Expr("{" + escaped + "}")
+}
+
+// Text is a quoted string literal.
+func Text(value string) Expr {
+	escaped := strings.NewReplacer(
+		`\`, `\\`,
+		`"`, `\"`,
+		"\n", `\n`,
+		"\r", `\r`,
+		"\t", `\t`,
+	).Replace(value)
+
+	return Expr(`"` + escaped + `"`)
+}
+
+// Number is a numeric literal.
+func Number(value float64) Expr {
+	return Expr(strconv.FormatFloat(value, 'f', -1, 64))
+}
+
+// True is the boolean true literal.
+func True() Expr {
+	return Call("TRUE")
+}
+
+// Call calls the named airtable function with the arguments.
+func Call(name string, args ...Expr) Expr {
+	parts := make([]string, len(args))
+	for i, arg := range args {
+		parts[i] = string(arg)
+	}
+
+	return Expr(name + "(" + strings.Join(parts, ", ") + ")")
+}
+
+// And is true when every expression is true. And with no expressions is always true.
+func And(exprs ...Expr) Expr {
+	if len(exprs) == 0 {
+		return True()
+	}
+
+	return Call("AND", exprs...)
+}
+
+// Or is true when any expression is true. Or with no expressions is always false.
+func Or(exprs ...Expr) Expr {
+	if len(exprs) == 0 {
+		return Call("FALSE")
+	}
+
+	return Call("OR", exprs...)
+}
+
+// Not negates the expression.
+func Not(expr Expr) Expr {
+	return Call("NOT", expr)
+}
+
+// Lower converts the expression to lower case.
+func Lower(expr Expr) Expr {
+	return Call("LOWER", expr)
+}
+
+// ArrayJoin joins the values of a linked or lookup field into a single string.
+func ArrayJoin(expr Expr) Expr {
+	return Call("ARRAYJOIN", expr)
+}
+
+// Search finds needle in haystack. It's truthy when the needle is found.
+func Search(needle, haystack Expr) Expr {
+	return Call("SEARCH", needle, haystack)
+}
+
+// Contains is a case insensitive search for value in a field, which may be a linked
+// field holding several values.
+func Contains(field string, value string) Expr {
+	return Search(Lower(Text(value)), Lower(ArrayJoin(Field(field))))
+}
+
+// Equals is a case insensitive comparison of value to a field, which may be a linked
+// field holding a single value.
+func Equals(field string, value string) Expr {
+	return Eq(Lower(Text(value)), Lower(ArrayJoin(Field(field))))
+}
+
+// Eq compares two expressions for equality.
+func Eq(a, b Expr) Expr {
+	return compare(a, "=", b)
+}
+
+// NotEq compares two expressions for inequality.
+func NotEq(a, b Expr) Expr {
+	return compare(a, "!=", b)
+}
+
+// Gt is true when a is greater than b.
+func Gt(a, b Expr) Expr {
+	return compare(a, ">", b)
+}
+
+// Gte is true when a is greater than or equal to b.
+func Gte(a, b Expr) Expr {
+	return compare(a, ">=", b)
+}
+
+// Lt is true when a is less than b.
+func Lt(a, b Expr) Expr {
+	return compare(a, "<", b)
+}
+
+// Lte is true when a is less than or equal to b.
+func Lte(a, b Expr) Expr {
+	return compare(a, "<=", b)
+}
+
+func compare(a Expr, operator string, b Expr) Expr {
+	return Expr("(" + string(a) + " " + operator + " " + string(b) + ")")
+}
+
+// DateTime is a date literal for the instant t.
+func DateTime(t time.Time) Expr {
+	return Call("DATETIME_PARSE", Text(t.UTC().Format(time.RFC3339)))
+}
+
+// Now is the current date and time according to airtable.
+func Now() Expr {
+	return Call("NOW")
+}
+
+// IsAfter is true when date a is after date b.
+func IsAfter(a, b Expr) Expr {
+	return Call("IS_AFTER", a, b)
+}
+
+// IsBefore is true when date a is before date b.
+func IsBefore(a, b Expr) Expr {
+	return Call("IS_BEFORE", a, b)
+}
+
+// IsSame is true when dates a and b are the same to the unit, e.g. "day".
+func IsSame(a, b Expr, unit string) Expr {
+	return Call("IS_SAME", a, b, Text(unit))
+}
@@ -0,0 +1,125 @@
+package formula_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/brewinski/home-lab-server/src/metro-volleyball-bot/vq/formula"
+)
+
+func TestFormula(t *testing.T) {
+	tests := []struct {
+		name string
+		expr formula.Expr
+		want string
+	}{
+		{
+			name: "field reference",
+			expr: formula.Field("Division"),
+			want: `{Division}`,
+		},
+		{
+			name: "field reference escapes closing braces",
+			expr: formula.Field(`Odd}Name`),
+			want: `{Odd\}Name}`,
+		},
+		{
+			name: "text literal",
+			expr: formula.Text("aces"),
+			want: `"aces"`,
+		},
+		{
+			name: "text literal escapes quotes",
+			expr: formula.Text(`aces", TRUE(), "`),
+			want: `"aces\", TRUE(), \""`,
+		},
+		{
+			name: "text literal escapes backslashes before quotes",
+			expr: formula.Text(`aces\`),
+			want: `"aces\\"`,
+		},
+		{
+			name: "text literal escapes new lines",
+			expr: formula.Text("line\nbreak"),
+			want: `"line\nbreak"`,
+		},
+		{
+			name: "number literal",
+			expr: formula.Number(2.5),
+			want: `2.5`,
+		},
+		{
+			name: "and joins every expression",
+			expr: formula.And(formula.Field("A"), formula.Field("B")),
+			want: `AND({A}, {B})`,
+		},
+		{
+			name: "and without expressions is true",
+			expr: formula.And(),
+			want: `TRUE()`,
+		},
+		{
+			name: "or joins every expression",
+			expr: formula.Or(formula.Field("A"), formula.Field("B")),
+			want: `OR({A}, {B})`,
+		},
+		{
+			name: "or without expressions is false",
+			expr: formula.Or(),
+			want: `FALSE()`,
+		},
+		{
+			name: "not",
+			expr: formula.Not(formula.Field("A")),
+			want: `NOT({A})`,
+		},
+		{
+			name: "search a lower case linked field",
+			expr: formula.Search(formula.Lower(formula.Text("Aces")), formula.Lower(formula.ArrayJoin(formula.Field("LinkTmA")))),
+			want: `SEARCH(LOWER("Aces"), LOWER(ARRAYJOIN({LinkTmA})))`,
+		},
+		{
+			name: "contains is a case insensitive search",
+			expr: formula.Contains("Display_Identifier", `"aces"`),
+			want: `SEARCH(LOWER("\"aces\""), LOWER(ARRAYJOIN({Display_Identifier})))`,
+		},
+		{
+			name: "equals is a case insensitive comparison",
+			expr: formula.Equals("Division", "MD"),
+			want: `(LOWER("MD") = LOWER(ARRAYJOIN({Division})))`,
+		},
+		{
+			name: "comparison operators",
+			expr: formula.And(
+				formula.Eq(formula.Field("A"), formula.Number(1)),
+				formula.NotEq(formula.Field("A"), formula.Number(2)),
+				formula.Gt(formula.Field("A"), formula.Number(3)),
+				formula.Gte(formula.Field("A"), formula.Number(4)),
+				formula.Lt(formula.Field("A"), formula.Number(5)),
+				formula.Lte(formula.Field("A"), formula.Number(6)),
+			),
+			want: `AND(({A} = 1), ({A} != 2), ({A} > 3), ({A} >= 4), ({A} < 5), ({A} <= 6))`,
+		},
+		{
+			name: "date literal is utc",
+			expr: formula.DateTime(time.Date(2024, time.March, 4, 19, 45, 0, 0, time.FixedZone("AEST", 10*60*60))),
+			want: `DATETIME_PARSE("2024-03-04T09:45:00Z")`,
+		},
+		{
+			name: "date comparisons",
+			expr: formula.Or(
+				formula.IsAfter(formula.Field("Date"), formula.Now()),
+				formula.IsBefore(formula.Field("Date"), formula.Now()),
+				formula.IsSame(formula.Field("Date"), formula.Now(), "day"),
+			),
+			want: `OR(IS_AFTER({Date}, NOW()), IS_BEFORE({Date}, NOW()), IS_SAME({Date}, NOW(), "day"))`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.expr.String(); got != tt.want {
+				t.Errorf("Expr.String() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
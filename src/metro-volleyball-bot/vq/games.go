@@ -2,9 +2,13 @@ package vq
 
 import (
 	"fmt"
+	"strings"
 	"time"
 )
 
+// games are scheduled in brisbane local time, which doesn't observe daylight savings.
+var gameLocation = time.FixedZone("AEST", 10*60*60)
+
 type GetGameResponseBody struct {
 	Records []GameRecord `json:"records"`
 	Offset  string       `json:"offset"`
@@ -21,15 +25,20 @@ type GetGamesRequestBody struct {
 }
 
 type GameFields struct {
-	TeamA                string `json:"LinkTmA"`
-	TeamB                string `json:"LinkTmB"`
-	DutyTeam             string `json:"LinkTmD"`
-	GameTime             string `json:"Time"`
-	GameDay              string `json:"Date"`
-	Round                string `json:"Round"`
-	Venue                string `json:"Venue"`
-	Court                string `json:"Court"`
-	MatchID              string `json:"Match"`
+	TeamA    string `json:"LinkTmA"`
+	TeamB    string `json:"LinkTmB"`
+	DutyTeam string `json:"LinkTmD"`
+	GameTime string `json:"Time"`
+	GameDay  string `json:"Date"`
+	Round    string `json:"Round"`
+	Venue    string `json:"Venue"`
+	Court    string `json:"Court"`
+	MatchID  string `json:"Match"`
+	// the result fields are empty until the game has been played.
+	SetsWonA             string `json:"SetsWonA"`
+	SetsWonB             string `json:"SetsWonB"`
+	PointsWonA           string `json:"PointsWonA"`
+	PointsWonB           string `json:"PointsWonB"`
 	CurrentTimeReference string `json:"CurrentTimeReference"`
 	Competition          string `json:"Competition"`
 }
@@ -41,5 +50,119 @@ type GameRecord struct {
 
 func (g GameRecord) ParseGameDayTime() (time.Time, error) {
 	gameDayTime := fmt.Sprintf("%s %s", g.Fields.GameDay, g.Fields.GameTime)
-	return time.Parse("2/1/2006 3:04pm", gameDayTime)
+	return time.ParseInLocation("2/1/2006 3:04pm", gameDayTime, gameLocation)
+}
+
+// GameResult is the score of a played game from team A's side.
+type GameResult struct {
+	SetsA   int
+	SetsB   int
+	PointsA int
+	PointsB int
+}
+
+// Result parses the score of the game. The bool is false when the game hasn't been
+// played, the points are left as zero when they weren't recorded.
+func (g GameRecord) Result() (GameResult, bool, error) {
+	f := g.Fields
+	if strings.TrimSpace(f.SetsWonA) == "" && strings.TrimSpace(f.SetsWonB) == "" {
+		return GameResult{}, false, nil
+	}
+
+	p := fieldParser{recordID: g.ID}
+	result := GameResult{
+		SetsA:   p.int("SetsWonA", f.SetsWonA),
+		SetsB:   p.int("SetsWonB", f.SetsWonB),
+		PointsA: p.int("PointsWonA", f.PointsWonA),
+		PointsB: p.int("PointsWonB", f.PointsWonB),
+	}
+
+	if err := p.err(); err != nil {
+		return GameResult{}, false, fmt.Errorf("Result() invalid game record: %w", err)
+	}
+
+	return result, true, nil
+}
+
+// Opponent returns the team playing against the provided team. Team names are matched
+// case insensitively and partially so "aces" will match "Aces Volleyball".
+func (g GameRecord) Opponent(team string) string {
+	if strings.Contains(strings.ToLower(g.Fields.TeamA), strings.ToLower(team)) {
+		return g.Fields.TeamB
+	}
+
+	return g.Fields.TeamA
+}
+
+// NextGame returns the first game scheduled after now. Games with a day or time that
+// can't be parsed are skipped. The bool is false when there are no upcoming games.
+func NextGame(games []GameRecord, now time.Time) (GameRecord, time.Time, bool) {
+	var next GameRecord
+	var nextStart time.Time
+	found := false
+
+	for _, game := range games {
+		start, err := game.ParseGameDayTime()
+		if err != nil {
+			continue
+		}
+
+		if !start.After(now) {
+			continue
+		}
+
+		if !found || start.Before(nextStart) {
+			next = game
+			nextStart = start
+			found = true
+		}
+	}
+
+	return next, nextStart, found
+}
+
+// DivisionGames returns the games played by teams on the division's ladder. The games
+// table holds every division's games and has no division field, so the ladder decides
+// which games belong to the division.
+func DivisionGames(games []GameRecord, ladder GetLadderResponseBody) []GameRecord {
+	teams := map[string]bool{}
+	for _, record := range ladder.Records {
+		teams[strings.ToLower(strings.TrimSpace(record.Fields.TeamName))] = true
+	}
+
+	var division []GameRecord
+	for _, game := range games {
+		if teams[strings.ToLower(strings.TrimSpace(game.Fields.TeamA))] || teams[strings.ToLower(strings.TrimSpace(game.Fields.TeamB))] {
+			division = append(division, game)
+		}
+	}
+
+	return division
+}
+
+// CompletedRound returns the round of the latest game that has a result. Games with a day
+// or time that can't be parsed are skipped. The bool is false when no game has been played.
+func CompletedRound(games []GameRecord) (string, bool) {
+	var round string
+	var latest time.Time
+	found := false
+
+	for _, game := range games {
+		if _, played, err := game.Result(); err != nil || !played {
+			continue
+		}
+
+		start, err := game.ParseGameDayTime()
+		if err != nil {
+			continue
+		}
+
+		if !found || start.After(latest) {
+			round = strings.TrimSpace(game.Fields.Round)
+			latest = start
+			found = true
+		}
+	}
+
+	return round, found
 }
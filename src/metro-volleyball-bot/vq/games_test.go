@@ -0,0 +1,187 @@
+package vq_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/brewinski/home-lab-server/src/metro-volleyball-bot/vq"
+)
+
+func TestGameRecord_ParseGameDayTime(t *testing.T) {
+	tests := []struct {
+		name    string
+		fields  vq.GameFields
+		want    time.Time
+		wantErr bool
+	}{
+		{
+			name:   "parse game day and time in brisbane time",
+			fields: vq.GameFields{GameDay: "4/3/2024", GameTime: "7:45pm"},
+			want:   time.Date(2024, time.March, 4, 9, 45, 0, 0, time.UTC),
+		},
+		{
+			name:    "return an error when the game day is missing",
+			fields:  vq.GameFields{GameTime: "7:45pm"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := vq.GameRecord{Fields: tt.fields}.ParseGameDayTime()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GameRecord.ParseGameDayTime() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !got.Equal(tt.want) {
+				t.Errorf("GameRecord.ParseGameDayTime() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGameRecord_Opponent(t *testing.T) {
+	game := vq.GameRecord{Fields: vq.GameFields{TeamA: "Aces", TeamB: "APG"}}
+
+	tests := []struct {
+		name string
+		team string
+		want string
+	}{
+		{name: "team a returns team b", team: "aces", want: "APG"},
+		{name: "team b returns team a", team: "APG", want: "Aces"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := game.Opponent(tt.team); got != tt.want {
+				t.Errorf("GameRecord.Opponent() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNextGame(t *testing.T) {
+	now := time.Date(2024, time.March, 10, 0, 0, 0, 0, time.UTC)
+
+	games := []vq.GameRecord{
+		{ID: "played", Fields: vq.GameFields{GameDay: "4/3/2024", GameTime: "7:45pm"}},
+		{ID: "later", Fields: vq.GameFields{GameDay: "18/3/2024", GameTime: "6:30pm"}},
+		{ID: "unscheduled", Fields: vq.GameFields{GameDay: "TBC"}},
+		{ID: "next", Fields: vq.GameFields{GameDay: "11/3/2024", GameTime: "9:00pm"}},
+	}
+
+	tests := []struct {
+		name      string
+		games     []vq.GameRecord
+		wantID    string
+		wantFound bool
+	}{
+		{
+			name:      "return the earliest game after now",
+			games:     games,
+			wantID:    "next",
+			wantFound: true,
+		},
+		{
+			name:      "return false when every game has been played",
+			games:     games[:1],
+			wantFound: false,
+		},
+		{
+			name:      "return false when there are no games",
+			games:     nil,
+			wantFound: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, start, found := vq.NextGame(tt.games, now)
+			if found != tt.wantFound {
+				t.Errorf("NextGame() found = %v, want %v", found, tt.wantFound)
+				return
+			}
+			if got.ID != tt.wantID {
+				t.Errorf("NextGame() = %v, want %v", got.ID, tt.wantID)
+			}
+			if found && !start.After(now) {
+				t.Errorf("NextGame() start = %v, want after %v", start, now)
+			}
+		})
+	}
+}
+
+func TestGameRecord_Result(t *testing.T) {
+	tests := []struct {
+		name       string
+		fields     vq.GameFields
+		want       vq.GameResult
+		wantPlayed bool
+		wantErr    bool
+	}{
+		{name: "not played", fields: vq.GameFields{}},
+		{name: "sets and points", fields: vq.GameFields{SetsWonA: "2", SetsWonB: "1", PointsWonA: "70", PointsWonB: "64"}, want: vq.GameResult{SetsA: 2, SetsB: 1, PointsA: 70, PointsB: 64}, wantPlayed: true},
+		{name: "sets without points", fields: vq.GameFields{SetsWonA: "0", SetsWonB: "3"}, want: vq.GameResult{SetsB: 3}, wantPlayed: true},
+		{name: "malformed sets", fields: vq.GameFields{SetsWonA: "W", SetsWonB: "L"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, played, err := vq.GameRecord{ID: "rec1", Fields: tt.fields}.Result()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GameRecord.Result() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want || played != tt.wantPlayed {
+				t.Errorf("GameRecord.Result() = %+v, %v, want %+v, %v", got, played, tt.want, tt.wantPlayed)
+			}
+		})
+	}
+}
+
+func TestCompletedRound(t *testing.T) {
+	games := []vq.GameRecord{
+		{ID: "r1", Fields: vq.GameFields{Round: "1", GameDay: "4/3/2024", GameTime: "7:45pm", SetsWonA: "3", SetsWonB: "0"}},
+		// a washed out game from round 2 is rescheduled after round 3.
+		{ID: "r2", Fields: vq.GameFields{Round: "2", GameDay: "25/3/2024", GameTime: "6:30pm"}},
+		{ID: "r3", Fields: vq.GameFields{Round: "3", GameDay: "18/3/2024", GameTime: "9:00pm", SetsWonA: "1", SetsWonB: "2"}},
+		{ID: "unscheduled", Fields: vq.GameFields{Round: "9", GameDay: "TBC", SetsWonA: "2", SetsWonB: "0"}},
+	}
+
+	tests := []struct {
+		name      string
+		games     []vq.GameRecord
+		want      string
+		wantFound bool
+	}{
+		{name: "the round of the latest played game", games: games, want: "3", wantFound: true},
+		{name: "only the first round played", games: games[:2], want: "1", wantFound: true},
+		{name: "nothing played", games: games[1:2], wantFound: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, found := vq.CompletedRound(tt.games)
+			if got != tt.want || found != tt.wantFound {
+				t.Errorf("CompletedRound() = %q, %v, want %q, %v", got, found, tt.want, tt.wantFound)
+			}
+		})
+	}
+}
+
+func TestDivisionGames(t *testing.T) {
+	ladder := vq.GetLadderResponseBody{Records: []vq.LadderRecord{
+		{ID: "rec1", Fields: vq.LadderFields{TeamName: "Aces", Division: "MD"}},
+		{ID: "rec2", Fields: vq.LadderFields{TeamName: "APG ", Division: "MD"}},
+	}}
+	games := []vq.GameRecord{
+		{ID: "md", Fields: vq.GameFields{TeamA: "Aces", TeamB: "apg"}},
+		{ID: "wd", Fields: vq.GameFields{TeamA: "Setters", TeamB: "Block Party"}},
+		{ID: "md-b", Fields: vq.GameFields{TeamA: "Dig Deep", TeamB: "APG"}},
+	}
+
+	var got []string
+	for _, game := range vq.DivisionGames(games, ladder) {
+		got = append(got, game.ID)
+	}
+
+	if want := []string{"md", "md-b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("DivisionGames() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,123 @@
+package vq
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Meeting is a played game between two teams, with the score from TeamA's side of the
+// head to head rather than the draw.
+type Meeting struct {
+	Game    GameRecord
+	Start   time.Time
+	Result  GameResult
+	Flipped bool
+}
+
+// HeadToHead is the record between two teams.
+type HeadToHead struct {
+	TeamA string
+	TeamB string
+	// Played are the games with a result, oldest first.
+	Played []Meeting
+	WinsA  int
+	WinsB  int
+	Draws  int
+	// Sets and points are totals across the played games, from TeamA's side.
+	Sets   Tally
+	Points Tally
+	// Next is the next scheduled meeting, HasNext is false when there isn't one.
+	Next      GameRecord
+	NextStart time.Time
+	HasNext   bool
+}
+
+// NewHeadToHead builds the record between teamA and teamB from games between them. Games
+// that haven't been played, or have a result that can't be parsed, are left out of the
+// record. Teams are matched the same way as GameRecord.Opponent.
+func NewHeadToHead(games []GameRecord, teamA, teamB string, now time.Time) HeadToHead {
+	h2h := HeadToHead{TeamA: teamA, TeamB: teamB}
+
+	var upcoming []GameRecord
+	for _, game := range games {
+		result, played, err := game.Result()
+		if err != nil {
+			continue
+		}
+
+		if !played {
+			upcoming = append(upcoming, game)
+			continue
+		}
+
+		// the draw may list the teams either way around.
+		flipped := !strings.Contains(strings.ToLower(game.Fields.TeamA), strings.ToLower(teamA))
+		if flipped {
+			result = GameResult{SetsA: result.SetsB, SetsB: result.SetsA, PointsA: result.PointsB, PointsB: result.PointsA}
+		}
+
+		start, _ := game.ParseGameDayTime()
+		h2h.Played = append(h2h.Played, Meeting{Game: game, Start: start, Result: result, Flipped: flipped})
+
+		switch {
+		case result.SetsA > result.SetsB:
+			h2h.WinsA++
+		case result.SetsB > result.SetsA:
+			h2h.WinsB++
+		default:
+			h2h.Draws++
+		}
+
+		h2h.Sets.For += result.SetsA
+		h2h.Sets.Against += result.SetsB
+		h2h.Sets.Played += result.SetsA + result.SetsB
+		h2h.Points.For += result.PointsA
+		h2h.Points.Against += result.PointsB
+		h2h.Points.Played += result.PointsA + result.PointsB
+	}
+
+	sort.SliceStable(h2h.Played, func(i, j int) bool {
+		return h2h.Played[i].Start.Before(h2h.Played[j].Start)
+	})
+
+	h2h.Next, h2h.NextStart, h2h.HasNext = NextGame(upcoming, now)
+
+	return h2h
+}
+
+// String formats the head to head as a discord message.
+func (h HeadToHead) String() string {
+	sb := strings.Builder{}
+	sb.WriteString(fmt.Sprintf("%s v %s\n", h.TeamA, h.TeamB))
+
+	if len(h.Played) == 0 {
+		sb.WriteString("\tno results yet\n")
+	} else {
+		sb.WriteString(fmt.Sprintf("\trecord: %s %d, %s %d", h.TeamA, h.WinsA, h.TeamB, h.WinsB))
+		if h.Draws > 0 {
+			sb.WriteString(fmt.Sprintf(", drawn %d", h.Draws))
+		}
+		sb.WriteString(fmt.Sprintf("\n\tsets: %d-%d\n", h.Sets.For, h.Sets.Against))
+		if h.Points.Played > 0 {
+			sb.WriteString(fmt.Sprintf("\tpoints: %d-%d\n", h.Points.For, h.Points.Against))
+		}
+
+		for _, meeting := range h.Played {
+			sb.WriteString(fmt.Sprintf("\tround %s, %s: sets %d-%d", meeting.Game.Fields.Round, meeting.Game.Fields.GameDay, meeting.Result.SetsA, meeting.Result.SetsB))
+			if meeting.Result.PointsA > 0 || meeting.Result.PointsB > 0 {
+				sb.WriteString(fmt.Sprintf(", points %d-%d", meeting.Result.PointsA, meeting.Result.PointsB))
+			}
+			sb.WriteString("\n")
+		}
+	}
+
+	if h.HasNext {
+		sb.WriteString(fmt.Sprintf("\tnext meeting: round %s <t:%d:F> at %s %s\n", h.Next.Fields.Round, h.NextStart.Unix(), h.Next.Fields.Venue, h.Next.Fields.Court))
+	} else {
+		sb.WriteString("\tno upcoming meetings\n")
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,70 @@
+package vq_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brewinski/home-lab-server/src/metro-volleyball-bot/vq"
+	"github.com/brewinski/home-lab-server/src/metro-volleyball-bot/vq/vqtest"
+)
+
+func TestNewHeadToHead(t *testing.T) {
+	games := []vq.GameRecord{
+		{ID: "3", Fields: vq.GameFields{TeamA: "Aces", TeamB: "APG", Round: "9", GameDay: "8/4/2024", GameTime: "7:45pm", Venue: "Auchenflower Stadium", Court: "Court 2"}},
+		{ID: "2", Fields: vq.GameFields{TeamA: "APG", TeamB: "Aces", Round: "5", GameDay: "4/3/2024", GameTime: "6:30pm", SetsWonA: "3", SetsWonB: "0", PointsWonA: "75", PointsWonB: "60"}},
+		{ID: "1", Fields: vq.GameFields{TeamA: "Aces", TeamB: "APG", Round: "1", GameDay: "5/2/2024", GameTime: "6:30pm", SetsWonA: "2", SetsWonB: "1"}},
+		{ID: "bad", Fields: vq.GameFields{TeamA: "Aces", TeamB: "APG", SetsWonA: "two"}},
+	}
+
+	got := vq.NewHeadToHead(games, "aces", "apg", time.Date(2024, time.March, 10, 0, 0, 0, 0, time.UTC))
+
+	if len(got.Played) != 2 || got.Played[0].Game.ID != "1" || got.Played[1].Game.ID != "2" {
+		t.Fatalf("NewHeadToHead() played = %+v, want the two results oldest first", got.Played)
+	}
+
+	if got.Played[1].Result != (vq.GameResult{SetsA: 0, SetsB: 3, PointsA: 60, PointsB: 75}) || !got.Played[1].Flipped {
+		t.Errorf("NewHeadToHead() result = %+v, want the score from aces' side", got.Played[1].Result)
+	}
+
+	if got.WinsA != 1 || got.WinsB != 1 || got.Draws != 0 {
+		t.Errorf("NewHeadToHead() record = %d-%d-%d, want 1-1-0", got.WinsA, got.WinsB, got.Draws)
+	}
+
+	if got.Sets != (vq.Tally{For: 2, Against: 4, Played: 6}) || got.Points != (vq.Tally{For: 60, Against: 75, Played: 135}) {
+		t.Errorf("NewHeadToHead() sets = %+v, points = %+v", got.Sets, got.Points)
+	}
+
+	if !got.HasNext || got.Next.ID != "3" {
+		t.Errorf("NewHeadToHead() next = %+v, %v, want round 9", got.Next, got.HasNext)
+	}
+
+	for _, want := range []string{"record: aces 1, apg 1", "sets: 2-4", "points: 60-75", "round 1, 5/2/2024: sets 2-1\n", "round 5, 4/3/2024: sets 0-3, points 60-75", "next meeting: round 9"} {
+		if !strings.Contains(got.String(), want) {
+			t.Errorf("HeadToHead.String() = %v, want it to contain %q", got.String(), want)
+		}
+	}
+}
+
+func TestClient_AllGamesBetween(t *testing.T) {
+	s := vqtest.NewServer(t)
+
+	games, err := s.NewClient().AllGamesBetween(context.Background(), "dig deep", "aces")
+	if err != nil {
+		t.Fatalf("Client.AllGamesBetween() error = %v", err)
+	}
+
+	if len(games) != 1 || games[0].Fields.MatchID != "MD-107" {
+		t.Fatalf("Client.AllGamesBetween() = %+v, want the round 3 game", games)
+	}
+
+	got := vq.NewHeadToHead(games, "Dig Deep", "Aces", time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC))
+	if got.WinsA != 1 || got.Sets.For != 3 || got.Sets.Against != 0 || got.HasNext {
+		t.Errorf("NewHeadToHead() = %+v, want a 3-0 win for Dig Deep and no next meeting", got)
+	}
+
+	if !strings.Contains(got.String(), "no upcoming meetings") {
+		t.Errorf("HeadToHead.String() = %v, want no upcoming meetings", got.String())
+	}
+}
@@ -1,10 +1,5 @@
 package vq
 
-import (
-	"fmt"
-	"strings"
-)
-
 type GetLadderRequestBody struct {
 	PageSize                   int `json:"page_size"`
 	AirtableResponseFormatting struct {
@@ -21,23 +16,6 @@ type GetLadderResponseBody struct {
 	Offset  string         `json:"offset"`
 }
 
-func (ladder GetLadderResponseBody) ToString() string {
-	sb := strings.Builder{}
-	sb.WriteString(fmt.Sprintf("Ladder: %s\n\n", "https://vqmetro23s3.softr.app/ladder-m1 ```"))
-
-	for _, team := range ladder.Records {
-		fields := team.Fields
-		sb.WriteString(fmt.Sprintf("%s. %s\n", fields.Rank, team.Fields.TeamName))
-		sb.WriteString(fmt.Sprintf("\tpoints: %s\n", fields.CompetitionPoints))
-		sb.WriteString(fmt.Sprintf("\tnext game: %s\n", fields.NextMatch_Detail))
-		sb.WriteString("\n")
-	}
-
-	sb.WriteString("```")
-
-	return sb.String()
-}
-
 type LadderRecord struct {
 	ID     string       `json:"id"`
 	Fields LadderFields `json:"fields"`
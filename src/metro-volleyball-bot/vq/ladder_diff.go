@@ -0,0 +1,183 @@
+package vq
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// LadderDiff is what changed between two ladders. Only changes that matter to the ladder
+// are tracked: rank, competition points, the W/L record and teams joining or leaving.
+type LadderDiff struct {
+	// Changes are the teams on both ladders that moved, gained points or played, in the
+	// order of the new ladder.
+	Changes []TeamChange
+	// Added are the teams only on the new ladder.
+	Added []Standing
+	// Removed are the teams only on the old ladder.
+	Removed []Standing
+}
+
+// TeamChange is a team's standing before and after a ladder update.
+type TeamChange struct {
+	Team string
+	Old  Standing
+	New  Standing
+}
+
+// RankChange is the number of places the team moved, positive when it moved up.
+func (c TeamChange) RankChange() int {
+	return c.Old.Rank - c.New.Rank
+}
+
+// PointsChange is the competition points gained since the old ladder.
+func (c TeamChange) PointsChange() float64 {
+	return c.New.CompetitionPoints - c.Old.CompetitionPoints
+}
+
+// RecordChanged reports whether the team's W/L record changed.
+func (c TeamChange) RecordChanged() bool {
+	return c.Old.Matches != c.New.Matches
+}
+
+// String describes the change, e.g. "Aces ↑2 to 3rd (+4 pts, 4-1-0)".
+func (c TeamChange) String() string {
+	sb := strings.Builder{}
+	sb.WriteString(c.Team)
+
+	switch moved := c.RankChange(); {
+	case moved > 0:
+		sb.WriteString(fmt.Sprintf(" ↑%d to %s", moved, Ordinal(c.New.Rank)))
+	case moved < 0:
+		sb.WriteString(fmt.Sprintf(" ↓%d to %s", -moved, Ordinal(c.New.Rank)))
+	default:
+		sb.WriteString(fmt.Sprintf(" stays %s", Ordinal(c.New.Rank)))
+	}
+
+	var details []string
+	if points := c.PointsChange(); points != 0 {
+		details = append(details, strconv.FormatFloat(points, 'f', -1, 64)+" pts")
+		if points > 0 {
+			details[0] = "+" + details[0]
+		}
+	}
+	if c.RecordChanged() {
+		details = append(details, c.New.Matches.String())
+	}
+
+	if len(details) > 0 {
+		sb.WriteString(" (" + strings.Join(details, ", ") + ")")
+	}
+
+	return sb.String()
+}
+
+// Empty reports whether the ladders are the same.
+func (d LadderDiff) Empty() bool {
+	return len(d.Changes) == 0 && len(d.Added) == 0 && len(d.Removed) == 0
+}
+
+// Movements returns the changes where the team's rank changed.
+func (d LadderDiff) Movements() []TeamChange {
+	var moved []TeamChange
+	for _, change := range d.Changes {
+		if change.RankChange() != 0 {
+			moved = append(moved, change)
+		}
+	}
+
+	return moved
+}
+
+// String describes the diff one line per team, ready to be posted as a notification.
+func (d LadderDiff) String() string {
+	var lines []string
+
+	for _, change := range d.Changes {
+		lines = append(lines, change.String())
+	}
+
+	for _, standing := range d.Added {
+		lines = append(lines, fmt.Sprintf("%s joined the ladder in %s", standing.Team, Ordinal(standing.Rank)))
+	}
+
+	for _, standing := range d.Removed {
+		lines = append(lines, fmt.Sprintf("%s left the ladder", standing.Team))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// DiffLadder compares two ladders by team. Team names are matched ignoring case and
+// surrounding whitespace, so formatting noise in the records doesn't show up as a change.
+func DiffLadder(old, new []Standing) LadderDiff {
+	previous := make(map[string]Standing, len(old))
+	for _, standing := range old {
+		previous[teamKey(standing.Team)] = standing
+	}
+
+	diff := LadderDiff{}
+	seen := make(map[string]bool, len(new))
+
+	for _, standing := range new {
+		key := teamKey(standing.Team)
+		seen[key] = true
+
+		before, ok := previous[key]
+		if !ok {
+			diff.Added = append(diff.Added, standing)
+			continue
+		}
+
+		change := TeamChange{Team: standing.Team, Old: before, New: standing}
+		if change.RankChange() != 0 || change.PointsChange() != 0 || change.RecordChanged() {
+			diff.Changes = append(diff.Changes, change)
+		}
+	}
+
+	for _, standing := range old {
+		if !seen[teamKey(standing.Team)] {
+			diff.Removed = append(diff.Removed, standing)
+		}
+	}
+
+	return diff
+}
+
+// DiffLadderResponses parses both ladders and compares them with DiffLadder.
+func DiffLadderResponses(old, new GetLadderResponseBody) (LadderDiff, error) {
+	oldStandings, err := old.Standings()
+	if err != nil {
+		return LadderDiff{}, fmt.Errorf("DiffLadderResponses() unable to parse old ladder: %w", err)
+	}
+
+	newStandings, err := new.Standings()
+	if err != nil {
+		return LadderDiff{}, fmt.Errorf("DiffLadderResponses() unable to parse new ladder: %w", err)
+	}
+
+	return DiffLadder(oldStandings, newStandings), nil
+}
+
+// Ordinal formats a rank as 1st, 2nd, 3rd etc.
+func Ordinal(n int) string {
+	suffix := "th"
+	switch n % 100 {
+	case 11, 12, 13:
+	default:
+		switch n % 10 {
+		case 1:
+			suffix = "st"
+		case 2:
+			suffix = "nd"
+		case 3:
+			suffix = "rd"
+		}
+	}
+
+	return strconv.Itoa(n) + suffix
+}
+
+func teamKey(team string) string {
+	return strings.ToLower(strings.TrimSpace(team))
+}
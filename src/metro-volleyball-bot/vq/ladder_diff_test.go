@@ -0,0 +1,110 @@
+package vq_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/brewinski/home-lab-server/src/metro-volleyball-bot/vq"
+)
+
+func TestDiffLadder(t *testing.T) {
+	aces := vq.Standing{Team: "Aces", Rank: 5, CompetitionPoints: 10, Matches: vq.Record{Played: 4, Won: 2, Lost: 2}}
+	apg := vq.Standing{Team: "APG", Rank: 3, CompetitionPoints: 12, Matches: vq.Record{Played: 4, Won: 3, Lost: 1}}
+	blockers := vq.Standing{Team: "Blockers", Rank: 4, CompetitionPoints: 11, Matches: vq.Record{Played: 4, Won: 2, Lost: 2}}
+
+	acesWon := vq.Standing{Team: "Aces", Rank: 3, CompetitionPoints: 14, Matches: vq.Record{Played: 5, Won: 3, Lost: 2}}
+	apgLost := vq.Standing{Team: "APG", Rank: 4, CompetitionPoints: 12, Matches: vq.Record{Played: 5, Won: 3, Lost: 2}}
+
+	tests := []struct {
+		name      string
+		old       []vq.Standing
+		new       []vq.Standing
+		want      vq.LadderDiff
+		wantLines string
+	}{
+		{
+			name: "no changes",
+			old:  []vq.Standing{apg, blockers, aces},
+			new:  []vq.Standing{apg, blockers, aces},
+			want: vq.LadderDiff{},
+		},
+		{
+			name: "match teams ignoring case and whitespace",
+			old:  []vq.Standing{aces},
+			new:  []vq.Standing{{Team: " aces ", Rank: 5, CompetitionPoints: 10, Matches: aces.Matches}},
+			want: vq.LadderDiff{},
+		},
+		{
+			name: "rank, points and record changes",
+			old:  []vq.Standing{apg, blockers, aces},
+			new:  []vq.Standing{acesWon, apgLost, blockers},
+			want: vq.LadderDiff{
+				Changes: []vq.TeamChange{
+					{Team: "Aces", Old: aces, New: acesWon},
+					{Team: "APG", Old: apg, New: apgLost},
+				},
+			},
+			wantLines: "Aces ↑2 to 3rd (+4 pts, 3-2-0)\nAPG ↓1 to 4th (3-2-0)",
+		},
+		{
+			name: "teams added and removed",
+			old:  []vq.Standing{apg, blockers},
+			new:  []vq.Standing{apg, aces},
+			want: vq.LadderDiff{
+				Added:   []vq.Standing{aces},
+				Removed: []vq.Standing{blockers},
+			},
+			wantLines: "Aces joined the ladder in 5th\nBlockers left the ladder",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := vq.DiffLadder(tt.old, tt.new)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DiffLadder() = %+v, want %+v", got, tt.want)
+			}
+
+			if got.Empty() != tt.want.Empty() {
+				t.Errorf("LadderDiff.Empty() = %v, want %v", got.Empty(), tt.want.Empty())
+			}
+
+			if lines := got.String(); lines != tt.wantLines {
+				t.Errorf("LadderDiff.String() = %q, want %q", lines, tt.wantLines)
+			}
+		})
+	}
+}
+
+func TestTeamChange_String(t *testing.T) {
+	tests := []struct {
+		name   string
+		change vq.TeamChange
+		want   string
+	}{
+		{
+			name:   "points without moving",
+			change: vq.TeamChange{Team: "Aces", Old: vq.Standing{Rank: 1, CompetitionPoints: 20}, New: vq.Standing{Rank: 1, CompetitionPoints: 21.5}},
+			want:   "Aces stays 1st (+1.5 pts)",
+		},
+		{
+			name:   "lost points to a penalty",
+			change: vq.TeamChange{Team: "Aces", Old: vq.Standing{Rank: 11, CompetitionPoints: 20}, New: vq.Standing{Rank: 12, CompetitionPoints: 18}},
+			want:   "Aces ↓1 to 12th (-2 pts)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.change.String(); got != tt.want {
+				t.Errorf("TeamChange.String() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOrdinal(t *testing.T) {
+	for n, want := range map[int]string{1: "1st", 2: "2nd", 3: "3rd", 4: "4th", 11: "11th", 12: "12th", 13: "13th", 21: "21st", 102: "102nd"} {
+		if got := vq.Ordinal(n); got != want {
+			t.Errorf("Ordinal(%d) = %v, want %v", n, got, want)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package vq
+
+import "fmt"
+
+const (
+	// maximum number of records the airtable proxy returns for a single request.
+	maxPageSize = 100
+	// upper bound on the number of pages followed for a single query. protects against
+	// the api handing back a cursor that never ends.
+	maxPages = 100
+)
+
+// collectPages calls fetch with the offset returned by the previous page until the api
+// stops returning an offset, and returns the records from every page.
+func collectPages[T any](fetch func(offset string) ([]T, string, error)) ([]T, error) {
+	var records []T
+	seen := map[string]bool{}
+	offset := ""
+
+	for page := 0; page < maxPages; page++ {
+		pageRecords, next, err := fetch(offset)
+		if err != nil {
+			return nil, fmt.Errorf("collectPages() page %d failed, got: %w", page, err)
+		}
+
+		records = append(records, pageRecords...)
+
+		if next == "" {
+			return records, nil
+		}
+
+		if seen[next] {
+			return nil, fmt.Errorf("collectPages() offset %q repeated on page %d", next, page)
+		}
+
+		seen[next] = true
+		offset = next
+	}
+
+	return nil, fmt.Errorf("collectPages() exceeded the maximum of %d pages", maxPages)
+}
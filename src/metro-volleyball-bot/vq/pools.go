@@ -0,0 +1,135 @@
+package vq
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Ladder views, how the standings are ordered and grouped when shown.
+const (
+	// ViewOverall is the division's overall ranking.
+	ViewOverall = "overall"
+	// ViewPools groups the teams by pool, ranked within each pool.
+	ViewPools = "pools"
+	// ViewCrossover ranks the teams across the pools.
+	ViewCrossover = "crossover"
+)
+
+// Views are the ladder views in the order they're offered.
+var Views = []string{ViewOverall, ViewPools, ViewCrossover}
+
+// PoolGroup is the standings of the teams in one pool.
+type PoolGroup struct {
+	// Pool is empty for teams that haven't been put in a pool.
+	Pool      string
+	Status    string
+	Standings []Standing
+}
+
+// HasPools reports whether any team on the ladder is in a pool.
+func HasPools(standings []Standing) bool {
+	for _, standing := range standings {
+		if standing.Pool != "" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// PoolStatus is the pool status shared by the standings, statuses are listed in ladder
+// order when teams disagree. It's empty when no team has one.
+func PoolStatus(standings []Standing) string {
+	var statuses []string
+	seen := map[string]bool{}
+	for _, standing := range standings {
+		if standing.PoolStatus == "" || seen[strings.ToLower(standing.PoolStatus)] {
+			continue
+		}
+
+		seen[strings.ToLower(standing.PoolStatus)] = true
+		statuses = append(statuses, standing.PoolStatus)
+	}
+
+	return strings.Join(statuses, ", ")
+}
+
+// GroupByPool splits the standings into pools ordered by name, with any teams not in a
+// pool last. Teams are ordered by their position in the pool, falling back to the overall
+// rank when the ladder doesn't provide one.
+func GroupByPool(standings []Standing) []PoolGroup {
+	index := map[string]int{}
+	var groups []PoolGroup
+	for _, standing := range standings {
+		i, ok := index[standing.Pool]
+		if !ok {
+			i = len(groups)
+			index[standing.Pool] = i
+			groups = append(groups, PoolGroup{Pool: standing.Pool})
+		}
+
+		groups[i].Standings = append(groups[i].Standings, standing)
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		if groups[i].Pool == "" || groups[j].Pool == "" {
+			return groups[j].Pool == "" && groups[i].Pool != ""
+		}
+
+		return groups[i].Pool < groups[j].Pool
+	})
+
+	for i := range groups {
+		groups[i].Status = PoolStatus(groups[i].Standings)
+		sortByRanking(groups[i].Standings, func(s Standing) int { return s.PositionalRanking })
+	}
+
+	return groups
+}
+
+// CrossoverOrder returns a copy of the standings ordered by their crossover ranking, falling
+// back to the overall rank when the ladder doesn't provide one.
+func CrossoverOrder(standings []Standing) []Standing {
+	ordered := append([]Standing(nil), standings...)
+	sortByRanking(ordered, func(s Standing) int { return s.CrossoverRanking })
+
+	return ordered
+}
+
+// ViewRank is the team's position shown in the view.
+func ViewRank(standing Standing, view string) int {
+	switch {
+	case view == ViewPools && standing.PositionalRanking > 0:
+		return standing.PositionalRanking
+	case view == ViewCrossover && standing.CrossoverRanking > 0:
+		return standing.CrossoverRanking
+	default:
+		return standing.Rank
+	}
+}
+
+// PoolName formats the pool for display.
+func PoolName(pool string) string {
+	if pool == "" {
+		return "No pool"
+	}
+
+	return fmt.Sprintf("Pool %s", pool)
+}
+
+// sortByRanking orders the standings by ranking, teams without one keep their overall rank
+// order after the ranked teams.
+func sortByRanking(standings []Standing, ranking func(Standing) int) {
+	sort.SliceStable(standings, func(i, j int) bool {
+		a, b := ranking(standings[i]), ranking(standings[j])
+		switch {
+		case a > 0 && b > 0 && a != b:
+			return a < b
+		case (a > 0) != (b > 0):
+			return a > 0
+		default:
+			return standings[i].Rank < standings[j].Rank
+		}
+	})
+}
@@ -0,0 +1,99 @@
+package vq_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/brewinski/home-lab-server/src/metro-volleyball-bot/vq"
+)
+
+func poolStandings() []vq.Standing {
+	return []vq.Standing{
+		{Rank: 1, Team: "Net Results", Pool: "A", PoolStatus: "Pool Play", PositionalRanking: 1, CrossoverRanking: 1},
+		{Rank: 2, Team: "Dig Deep", Pool: "B", PoolStatus: "Pool Play", PositionalRanking: 1, CrossoverRanking: 2},
+		{Rank: 3, Team: "Blockers", Pool: "A", PoolStatus: "Pool Play", PositionalRanking: 2, CrossoverRanking: 4},
+		{Rank: 4, Team: "Spike Force", Pool: "B", PoolStatus: "pool play", PositionalRanking: 2, CrossoverRanking: 3},
+		{Rank: 5, Team: "Latecomers"},
+		{Rank: 6, Team: "Aces", Pool: "A", PoolStatus: "Finals"},
+	}
+}
+
+func teams(standings []vq.Standing) []string {
+	var names []string
+	for _, standing := range standings {
+		names = append(names, standing.Team)
+	}
+
+	return names
+}
+
+func TestGroupByPool(t *testing.T) {
+	groups := vq.GroupByPool(poolStandings())
+
+	type group struct {
+		Pool   string
+		Status string
+		Teams  []string
+	}
+
+	var got []group
+	for _, g := range groups {
+		got = append(got, group{Pool: g.Pool, Status: g.Status, Teams: teams(g.Standings)})
+	}
+
+	want := []group{
+		{Pool: "A", Status: "Pool Play, Finals", Teams: []string{"Net Results", "Blockers", "Aces"}},
+		{Pool: "B", Status: "Pool Play", Teams: []string{"Dig Deep", "Spike Force"}},
+		{Pool: "", Status: "", Teams: []string{"Latecomers"}},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GroupByPool() = %+v, want %+v", got, want)
+	}
+}
+
+func TestCrossoverOrder(t *testing.T) {
+	standings := poolStandings()
+	got := teams(vq.CrossoverOrder(standings))
+	want := []string{"Net Results", "Dig Deep", "Spike Force", "Blockers", "Latecomers", "Aces"}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CrossoverOrder() = %v, want %v", got, want)
+	}
+
+	if standings[2].Team != "Blockers" {
+		t.Errorf("CrossoverOrder() reordered the standings passed in")
+	}
+}
+
+func TestViewRank(t *testing.T) {
+	standing := vq.Standing{Rank: 3, PositionalRanking: 2, CrossoverRanking: 4}
+
+	tests := []struct {
+		view     string
+		standing vq.Standing
+		want     int
+	}{
+		{view: vq.ViewOverall, standing: standing, want: 3},
+		{view: vq.ViewPools, standing: standing, want: 2},
+		{view: vq.ViewCrossover, standing: standing, want: 4},
+		{view: vq.ViewCrossover, standing: vq.Standing{Rank: 5}, want: 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.view, func(t *testing.T) {
+			if got := vq.ViewRank(tt.standing, tt.view); got != tt.want {
+				t.Errorf("ViewRank() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPoolStatus(t *testing.T) {
+	if got := vq.PoolStatus(poolStandings()); got != "Pool Play, Finals" {
+		t.Errorf("PoolStatus() = %q, want %q", got, "Pool Play, Finals")
+	}
+
+	if vq.HasPools([]vq.Standing{{Team: "Aces"}}) {
+		t.Errorf("HasPools() = true, want false without pools")
+	}
+}
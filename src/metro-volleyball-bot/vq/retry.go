@@ -0,0 +1,123 @@
+package vq
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultMaxRetries     = 3
+	defaultRetryBaseDelay = 500 * time.Millisecond
+	defaultRetryMaxDelay  = 10 * time.Second
+)
+
+// retryPolicy controls how failed requests are retried.
+type retryPolicy struct {
+	maxRetries int
+	baseDelay  time.Duration
+	maxDelay   time.Duration
+}
+
+func newRetryPolicy(maxRetries int, baseDelay, maxDelay time.Duration) retryPolicy {
+	if maxRetries == 0 {
+		maxRetries = defaultMaxRetries
+	}
+
+	if maxRetries < 0 {
+		maxRetries = 0
+	}
+
+	if baseDelay <= 0 {
+		baseDelay = defaultRetryBaseDelay
+	}
+
+	if maxDelay <= 0 {
+		maxDelay = defaultRetryMaxDelay
+	}
+
+	return retryPolicy{
+		maxRetries: maxRetries,
+		baseDelay:  baseDelay,
+		maxDelay:   maxDelay,
+	}
+}
+
+// delay returns how long to wait before the retry following attempt. The exponential
+// backoff is jittered between half and the full delay so concurrent callers don't retry
+// in lock step. A Retry-After from the server is used when it's longer than the backoff,
+// capped at the max delay so a server asking for hours doesn't stall the bot.
+func (p retryPolicy) delay(attempt int, retryAfter time.Duration) time.Duration {
+	backoff := p.maxDelay
+	// stop doubling once the cap is reached, large shifts overflow.
+	if attempt < 30 && p.baseDelay<<attempt < p.maxDelay {
+		backoff = p.baseDelay << attempt
+	}
+
+	half := backoff / 2
+	backoff = half + time.Duration(rand.Int63n(int64(half)+1))
+
+	if retryAfter > backoff {
+		return min(retryAfter, p.maxDelay)
+	}
+
+	return backoff
+}
+
+// retryableError marks errors that are worth retrying.
+type retryableError struct {
+	err error
+}
+
+func (e retryableError) Error() string {
+	return e.err.Error()
+}
+
+func (e retryableError) Unwrap() error {
+	return e.err
+}
+
+func isRetryable(err error) bool {
+	var retryable retryableError
+	return errors.As(err, &retryable)
+}
+
+// parseRetryAfter reads a Retry-After header in either the delay-seconds or http-date
+// format. It returns 0 when the header is missing or invalid.
+func parseRetryAfter(header string, now time.Time) time.Duration {
+	header = strings.TrimSpace(header)
+	if header == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(header); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+
+		return time.Duration(seconds) * time.Second
+	}
+
+	if date, err := http.ParseTime(header); err == nil && date.After(now) {
+		return date.Sub(now)
+	}
+
+	return 0
+}
+
+// sleep waits for d or until the context is done, whichever happens first.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
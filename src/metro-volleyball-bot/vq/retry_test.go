@@ -0,0 +1,183 @@
+package vq_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/brewinski/home-lab-server/src/metro-volleyball-bot/vq"
+)
+
+func TestClient_Retries(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		// statuses returned for each request, the last status repeats once they run out.
+		statuses     []int
+		retryAfter   string
+		maxRetries   int
+		maxDelay     time.Duration
+		wantRequests int32
+		wantMinDelay time.Duration
+		wantMaxDelay time.Duration
+		wantErr      bool
+	}{
+		{
+			name:         "retry server errors until the request succeeds",
+			statuses:     []int{http.StatusServiceUnavailable, http.StatusBadGateway, http.StatusOK},
+			wantRequests: 3,
+		},
+		{
+			name:         "don't retry client errors",
+			statuses:     []int{http.StatusBadRequest},
+			wantRequests: 1,
+			wantErr:      true,
+		},
+		{
+			name:         "give up once the retries are exhausted",
+			statuses:     []int{http.StatusTooManyRequests},
+			maxRetries:   2,
+			wantRequests: 3,
+			wantErr:      true,
+		},
+		{
+			name:         "wait for the retry after header before retrying",
+			statuses:     []int{http.StatusTooManyRequests, http.StatusOK},
+			retryAfter:   "1",
+			maxDelay:     2 * time.Second,
+			wantRequests: 2,
+			wantMinDelay: time.Second,
+		},
+		{
+			name:         "cap the retry after header at the max delay",
+			statuses:     []int{http.StatusTooManyRequests, http.StatusOK},
+			retryAfter:   "3600",
+			wantRequests: 2,
+			wantMaxDelay: time.Second,
+		},
+		{
+			name:         "negative max retries disables retries",
+			statuses:     []int{http.StatusInternalServerError},
+			maxRetries:   -1,
+			wantRequests: 1,
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var requests atomic.Int32
+			testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				request := int(requests.Add(1)) - 1
+				status := tt.statuses[min(request, len(tt.statuses)-1)]
+
+				if tt.retryAfter != "" {
+					w.Header().Set("Retry-After", tt.retryAfter)
+				}
+
+				w.WriteHeader(status)
+				json.NewEncoder(w).Encode(ladderResponseBody)
+			}))
+			defer testServer.Close()
+
+			c := vq.NewClient(vq.ClientConfig{
+				Client:         &http.Client{},
+				ApiUrl:         testServer.URL,
+				MaxRetries:     tt.maxRetries,
+				RetryBaseDelay: time.Millisecond,
+				RetryMaxDelay:  max(tt.maxDelay, 5*time.Millisecond),
+			})
+
+			start := time.Now()
+			_, err := c.GetLadderPage(context.Background(), 100, "")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Client.GetLadderPage() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if got := requests.Load(); got != tt.wantRequests {
+				t.Errorf("Client.GetLadderPage() requests = %d, want %d", got, tt.wantRequests)
+			}
+
+			elapsed := time.Since(start)
+			if elapsed < tt.wantMinDelay {
+				t.Errorf("Client.GetLadderPage() elapsed = %v, want at least %v", elapsed, tt.wantMinDelay)
+			}
+
+			if tt.wantMaxDelay > 0 && elapsed > tt.wantMaxDelay {
+				t.Errorf("Client.GetLadderPage() elapsed = %v, want at most %v", elapsed, tt.wantMaxDelay)
+			}
+		})
+	}
+}
+
+func TestClient_ContextCancelled(t *testing.T) {
+	t.Parallel()
+
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer testServer.Close()
+
+	c := vq.NewClient(vq.ClientConfig{
+		Client:         &http.Client{},
+		ApiUrl:         testServer.URL,
+		MaxRetries:     10,
+		RetryBaseDelay: time.Second,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := c.GetLadder(ctx)
+	if err == nil {
+		t.Errorf("Client.GetLadder() expected an error when the context is cancelled")
+	}
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Client.GetLadder() elapsed = %v, want the request abandoned when the context ends", elapsed)
+	}
+}
+
+func TestClient_ConcurrentRequests(t *testing.T) {
+	t.Parallel()
+
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if pageId := r.Header.Get("softr-page-id"); pageId == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		json.NewEncoder(w).Encode(ladderResponseBody)
+	}))
+	defer testServer.Close()
+
+	c := vq.NewClient(vq.ClientConfig{
+		Client: &http.Client{},
+		ApiUrl: testServer.URL,
+	})
+
+	workers := 20
+	var wg sync.WaitGroup
+	wg.Add(workers)
+
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			if _, err := c.GetLadder(context.Background()); err != nil {
+				t.Errorf("Client.GetLadder() error = %v", err)
+			}
+		}()
+	}
+
+	wg.Wait()
+}
@@ -0,0 +1,268 @@
+package vq
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Standing is a team's position on the ladder with the numeric fields parsed out of the
+// string formatted LadderFields.
+type Standing struct {
+	ID                string
+	Rank              int
+	Team              string
+	Division          string
+	Matches           Record
+	Sets              Tally
+	Points            Tally
+	SetRatio          float64
+	PointRatio        float64
+	CompetitionPoints float64
+	AggregatedPoints  float64
+	Penalties         float64
+	// Pool is empty when the division isn't split into pools. PositionalRanking is the
+	// team's place within its pool and CrossoverRanking its place across the pools, both
+	// are 0 when the ladder doesn't provide them.
+	Pool              string
+	PoolStatus        string
+	PositionalRanking int
+	CrossoverRanking  int
+}
+
+// Record is the number of matches a team has played and the outcome of each.
+type Record struct {
+	Played       int
+	Won          int
+	Lost         int
+	Drawn        int
+	Forfeit      int
+	Disqualified int
+}
+
+// String formats the record as W-L-D.
+func (r Record) String() string {
+	return fmt.Sprintf("%d-%d-%d", r.Won, r.Lost, r.Drawn)
+}
+
+// Tally is a count of sets or points won and lost.
+type Tally struct {
+	For     int
+	Against int
+	Played  int
+}
+
+// Ratio is For divided by Against. It's +Inf when nothing has been conceded and 0 when
+// nothing has been played.
+func (t Tally) Ratio() float64 {
+	if t.Against == 0 {
+		if t.For == 0 {
+			return 0
+		}
+		return math.Inf(1)
+	}
+
+	return float64(t.For) / float64(t.Against)
+}
+
+// Difference is For minus Against.
+func (t Tally) Difference() int {
+	return t.For - t.Against
+}
+
+// FieldError is returned when a ladder field can't be parsed as a number.
+type FieldError struct {
+	RecordID string
+	Field    string
+	Value    string
+	Err      error
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("record[%s] field[%s] value[%q]: %s", e.RecordID, e.Field, e.Value, e.Err)
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// Standing converts the ladder record into a typed Standing. Empty fields are treated as
+// zero. Every field that fails to parse is reported in the returned error, which can be
+// inspected with errors.As for a *FieldError.
+func (r LadderRecord) Standing() (Standing, error) {
+	p := fieldParser{recordID: r.ID}
+	f := r.Fields
+
+	team := f.TeamName
+	if team == "" {
+		team = f.TeamNameLookup
+	}
+
+	standing := Standing{
+		ID:       r.ID,
+		Rank:     p.int("Rank", f.Rank),
+		Team:     team,
+		Division: f.Division,
+		Matches: Record{
+			Played:       p.int("Matches Played", f.MatchesPlayed),
+			Won:          p.int("Matches Won", f.MatchesWon),
+			Lost:         p.int("Matches Lost", f.MatchesLost),
+			Drawn:        p.int("Matches Drawn", f.MatchesDrawn),
+			Forfeit:      p.int("MatchesForfeit", f.MatchesForfeit),
+			Disqualified: p.int("MatchesDisqualified", f.MatchesDisqualified),
+		},
+		Sets: Tally{
+			For:     p.int("Sets For", f.SetsFor),
+			Against: p.int("Sets Against", f.SetsAgainst),
+			Played:  p.int("TotalSetsPlayed", f.TotalSetsPlayed),
+		},
+		Points: Tally{
+			For:     p.int("Points For", f.PointsFor),
+			Against: p.int("Points Against", f.PointsAgainst),
+			Played:  p.int("TotalPointsPlayed", f.TotalPointsPlayed),
+		},
+		SetRatio:          p.float("SetRatio", f.SetRatio),
+		PointRatio:        p.float("PointRatio", f.PointRatio),
+		CompetitionPoints: p.float("Competition Points", f.CompetitionPoints),
+		AggregatedPoints:  p.float("Aggregated Points", f.AggregatedPoints),
+		Penalties:         p.float("Penalties", f.Penalties),
+		Pool:              strings.TrimSpace(f.Pool),
+		PoolStatus:        strings.TrimSpace(f.PoolStatus),
+		PositionalRanking: p.int("PositionalRanking", f.PositionalRanking),
+		CrossoverRanking:  p.int("CrossoverRanking", f.CrossoverRanking),
+	}
+
+	if err := p.err(); err != nil {
+		return Standing{}, fmt.Errorf("Standing() invalid ladder record: %w", err)
+	}
+
+	return standing, nil
+}
+
+// Standings converts every record on the ladder, keeping the ladder order. It fails if
+// any record is malformed.
+func (ladder GetLadderResponseBody) Standings() ([]Standing, error) {
+	standings := make([]Standing, 0, len(ladder.Records))
+	var errs []error
+
+	for _, record := range ladder.Records {
+		standing, err := record.Standing()
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		standings = append(standings, standing)
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	return standings, nil
+}
+
+// PointsScheme is the competition points awarded for each match result.
+type PointsScheme struct {
+	Win        float64
+	Loss       float64
+	Draw       float64
+	Set        float64
+	Forfeit    float64
+	Disqualify float64
+}
+
+// PointsScheme parses the points awarded by the competition from the record's Pts_
+// fields.
+func (r LadderRecord) PointsScheme() (PointsScheme, error) {
+	p := fieldParser{recordID: r.ID}
+	f := r.Fields
+
+	scheme := PointsScheme{
+		Win:        p.float("Pts_Win", f.PtsWin),
+		Loss:       p.float("Pts_Loss", f.PtsLoss),
+		Draw:       p.float("Pts_Draw", f.PtsDraw),
+		Set:        p.float("Pts_Sets", f.PtsSets),
+		Forfeit:    p.float("Pts_Forfeit", f.PtsForfeit),
+		Disqualify: p.float("Pts_Disqualify", f.PtsDisqualify),
+	}
+
+	if err := p.err(); err != nil {
+		return PointsScheme{}, fmt.Errorf("PointsScheme() invalid ladder record: %w", err)
+	}
+
+	return scheme, nil
+}
+
+// PointsScheme returns the points scheme of the first record, every team in a division
+// shares the same scheme.
+func (ladder GetLadderResponseBody) PointsScheme() (PointsScheme, error) {
+	if len(ladder.Records) == 0 {
+		return PointsScheme{}, errors.New("PointsScheme() the ladder has no records")
+	}
+
+	return ladder.Records[0].PointsScheme()
+}
+
+// fieldParser parses string formatted airtable fields and collects the errors so every
+// bad field in a record is reported at once.
+type fieldParser struct {
+	recordID string
+	errs     []error
+}
+
+func (p *fieldParser) int(field, value string) int {
+	cleaned := cleanNumber(value)
+	if cleaned == "" {
+		return 0
+	}
+
+	if i, err := strconv.Atoi(cleaned); err == nil {
+		return i
+	}
+
+	// whole numbers are sometimes formatted with a decimal place, e.g. "3.0".
+	f, err := strconv.ParseFloat(cleaned, 64)
+	if err != nil || f != math.Trunc(f) || math.IsInf(f, 0) {
+		if err == nil {
+			err = errors.New("not a whole number")
+		}
+		p.errs = append(p.errs, &FieldError{p.recordID, field, value, err})
+		return 0
+	}
+
+	return int(f)
+}
+
+func (p *fieldParser) float(field, value string) float64 {
+	cleaned := cleanNumber(value)
+	if cleaned == "" {
+		return 0
+	}
+
+	if cleaned == "∞" {
+		return math.Inf(1)
+	}
+
+	f, err := strconv.ParseFloat(cleaned, 64)
+	if err != nil || math.IsNaN(f) {
+		if err == nil {
+			err = errors.New("not a number")
+		}
+		p.errs = append(p.errs, &FieldError{p.recordID, field, value, err})
+		return 0
+	}
+
+	return f
+}
+
+func (p *fieldParser) err() error {
+	return errors.Join(p.errs...)
+}
+
+// cleanNumber strips the formatting airtable adds to numbers, e.g. "1,234 " -> "1234".
+func cleanNumber(value string) string {
+	return strings.ReplaceAll(strings.TrimSpace(value), ",", "")
+}
@@ -0,0 +1,185 @@
+package vq_test
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/brewinski/home-lab-server/src/metro-volleyball-bot/vq"
+)
+
+func TestLadderRecord_Standing(t *testing.T) {
+	tests := []struct {
+		name       string
+		record     vq.LadderRecord
+		want       vq.Standing
+		wantFields []string
+	}{
+		{
+			name: "parse the numeric ladder fields",
+			record: vq.LadderRecord{
+				ID: "rec1",
+				Fields: vq.LadderFields{
+					Rank:                "2",
+					TeamName:            "Aces",
+					Division:            "MD",
+					MatchesPlayed:       "6",
+					MatchesWon:          "4",
+					MatchesLost:         "1",
+					MatchesDrawn:        "1",
+					SetsFor:             "13",
+					SetsAgainst:         "5",
+					TotalSetsPlayed:     "18",
+					PointsFor:           "1,012",
+					PointsAgainst:       "850",
+					TotalPointsPlayed:   "1862",
+					SetRatio:            "2.6",
+					PointRatio:          "1.19",
+					CompetitionPoints:   "25",
+					AggregatedPoints:    "25.5",
+					Penalties:           "",
+					MatchesForfeit:      "0",
+					MatchesDisqualified: "0.0",
+				},
+			},
+			want: vq.Standing{
+				ID:                "rec1",
+				Rank:              2,
+				Team:              "Aces",
+				Division:          "MD",
+				Matches:           vq.Record{Played: 6, Won: 4, Lost: 1, Drawn: 1},
+				Sets:              vq.Tally{For: 13, Against: 5, Played: 18},
+				Points:            vq.Tally{For: 1012, Against: 850, Played: 1862},
+				SetRatio:          2.6,
+				PointRatio:        1.19,
+				CompetitionPoints: 25,
+				AggregatedPoints:  25.5,
+			},
+		},
+		{
+			name: "fall back to the team name lookup",
+			record: vq.LadderRecord{
+				ID:     "rec2",
+				Fields: vq.LadderFields{Rank: "1", TeamNameLookup: "APG"},
+			},
+			want: vq.Standing{ID: "rec2", Rank: 1, Team: "APG"},
+		},
+		{
+			name: "report every malformed field",
+			record: vq.LadderRecord{
+				ID: "rec3",
+				Fields: vq.LadderFields{
+					Rank:              "first",
+					CompetitionPoints: "lots",
+					MatchesWon:        "1.5",
+				},
+			},
+			wantFields: []string{"Rank", "Matches Won", "Competition Points"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.record.Standing()
+			if (err != nil) != (len(tt.wantFields) > 0) {
+				t.Errorf("LadderRecord.Standing() error = %v, wantFields %v", err, tt.wantFields)
+				return
+			}
+
+			for _, field := range tt.wantFields {
+				if !hasFieldError(err, field) {
+					t.Errorf("LadderRecord.Standing() error = %v, want a field error for %s", err, field)
+				}
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("LadderRecord.Standing() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// hasFieldError reports whether err contains a *vq.FieldError for the field.
+func hasFieldError(err error, field string) bool {
+	var joined interface{ Unwrap() []error }
+	if errors.As(err, &joined) {
+		for _, err := range joined.Unwrap() {
+			if hasFieldError(err, field) {
+				return true
+			}
+		}
+	}
+
+	var fieldErr *vq.FieldError
+	return errors.As(err, &fieldErr) && fieldErr.Field == field
+}
+
+func TestGetLadderResponseBody_Standings(t *testing.T) {
+	ladder := vq.GetLadderResponseBody{
+		Records: []vq.LadderRecord{
+			{ID: "1", Fields: vq.LadderFields{Rank: "1", TeamName: "Aces"}},
+			{ID: "2", Fields: vq.LadderFields{Rank: "2", TeamName: "APG"}},
+		},
+	}
+
+	got, err := ladder.Standings()
+	if err != nil {
+		t.Fatalf("GetLadderResponseBody.Standings() error = %v", err)
+	}
+
+	if len(got) != 2 || got[0].Team != "Aces" || got[1].Rank != 2 {
+		t.Errorf("GetLadderResponseBody.Standings() = %+v, want the ladder order kept", got)
+	}
+
+	ladder.Records = append(ladder.Records, vq.LadderRecord{ID: "3", Fields: vq.LadderFields{Rank: "?"}})
+	if _, err := ladder.Standings(); err == nil {
+		t.Errorf("GetLadderResponseBody.Standings() expected an error for a malformed record")
+	}
+}
+
+func TestTally(t *testing.T) {
+	tests := []struct {
+		name      string
+		tally     vq.Tally
+		wantRatio float64
+		wantDiff  int
+	}{
+		{name: "ratio of won to lost", tally: vq.Tally{For: 6, Against: 4}, wantRatio: 1.5, wantDiff: 2},
+		{name: "nothing conceded is infinite", tally: vq.Tally{For: 6}, wantRatio: math.Inf(1), wantDiff: 6},
+		{name: "nothing played is zero", tally: vq.Tally{}, wantRatio: 0, wantDiff: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.tally.Ratio(); got != tt.wantRatio {
+				t.Errorf("Tally.Ratio() = %v, want %v", got, tt.wantRatio)
+			}
+			if got := tt.tally.Difference(); got != tt.wantDiff {
+				t.Errorf("Tally.Difference() = %v, want %v", got, tt.wantDiff)
+			}
+		})
+	}
+}
+
+func TestRecord_String(t *testing.T) {
+	if got := (vq.Record{Won: 4, Lost: 1, Drawn: 1}).String(); got != "4-1-1" {
+		t.Errorf("Record.String() = %v, want 4-1-1", got)
+	}
+}
+
+func TestGetLadderResponseBody_PointsScheme(t *testing.T) {
+	ladder := vq.GetLadderResponseBody{
+		Records: []vq.LadderRecord{
+			{ID: "1", Fields: vq.LadderFields{PtsWin: "3", PtsLoss: "0", PtsDraw: "1", PtsSets: "1", PtsForfeit: "-3", PtsDisqualify: "-5"}},
+		},
+	}
+
+	got, err := ladder.PointsScheme()
+	want := vq.PointsScheme{Win: 3, Draw: 1, Set: 1, Forfeit: -3, Disqualify: -5}
+	if err != nil || got != want {
+		t.Errorf("GetLadderResponseBody.PointsScheme() = %+v, %v, want %+v", got, err, want)
+	}
+
+	if _, err := (vq.GetLadderResponseBody{}).PointsScheme(); err == nil {
+		t.Errorf("GetLadderResponseBody.PointsScheme() expected an error for an empty ladder")
+	}
+}
@@ -0,0 +1,284 @@
+package vqtest
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// evalFormula evaluates a filterByFormula expression against a record's fields. It only
+// understands the shapes the vq client builds with the vq/formula package: AND, OR,
+// TRUE and FALSE over formula.Contains and formula.Equals. Anything else is an error so a
+// new kind of filter fails the tests rather than quietly matching every record.
+func evalFormula(formula string, fields map[string]any) (bool, error) {
+	if strings.TrimSpace(formula) == "" {
+		return true, nil
+	}
+
+	p := &formulaParser{input: formula, fields: fields}
+
+	value, err := p.expr()
+	if err != nil {
+		return false, err
+	}
+
+	if p.skipSpace(); p.pos < len(p.input) {
+		return false, fmt.Errorf("unexpected %q at %d", p.input[p.pos:], p.pos)
+	}
+
+	match, ok := value.(bool)
+	if !ok {
+		return false, fmt.Errorf("formula is %T, want a condition", value)
+	}
+
+	return match, nil
+}
+
+// formulaParser evaluates while it parses, the formulas are tiny so there's no need to
+// build a tree first. Values are bools for conditions and strings for everything else.
+type formulaParser struct {
+	input  string
+	pos    int
+	fields map[string]any
+}
+
+func (p *formulaParser) expr() (any, error) {
+	p.skipSpace()
+	if p.pos >= len(p.input) {
+		return nil, fmt.Errorf("unexpected end of formula")
+	}
+
+	switch c := p.input[p.pos]; {
+	case c == '(':
+		return p.equals()
+	case c == '"':
+		return p.text()
+	case c == '{':
+		return p.field()
+	case unicode.IsLetter(rune(c)):
+		return p.call()
+	default:
+		return nil, fmt.Errorf("unexpected %q at %d", c, p.pos)
+	}
+}
+
+// equals reads the (a = b) comparison built by formula.Equals.
+func (p *formulaParser) equals() (any, error) {
+	p.pos++
+
+	left, err := p.expr()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.expect('='); err != nil {
+		return nil, err
+	}
+
+	right, err := p.expr()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.expect(')'); err != nil {
+		return nil, err
+	}
+
+	s, err := stringArgs("=", []any{left, right}, 2)
+	if err != nil {
+		return nil, err
+	}
+
+	return s[0] == s[1], nil
+}
+
+// text reads a string literal as escaped by formula.Text.
+func (p *formulaParser) text() (any, error) {
+	p.pos++
+	sb := strings.Builder{}
+
+	for p.pos < len(p.input) {
+		c := p.input[p.pos]
+		p.pos++
+
+		switch c {
+		case '"':
+			return sb.String(), nil
+		case '\\':
+			if p.pos >= len(p.input) {
+				return nil, fmt.Errorf("unterminated escape")
+			}
+			escaped := p.input[p.pos]
+			p.pos++
+			switch escaped {
+			case 'n':
+				sb.WriteByte('\n')
+			case 'r':
+				sb.WriteByte('\r')
+			case 't':
+				sb.WriteByte('\t')
+			default:
+				sb.WriteByte(escaped)
+			}
+		default:
+			sb.WriteByte(c)
+		}
+	}
+
+	return nil, fmt.Errorf("unterminated string")
+}
+
+// field reads a field reference as escaped by formula.Field. Missing fields are empty.
+func (p *formulaParser) field() (any, error) {
+	p.pos++
+	sb := strings.Builder{}
+
+	for p.pos < len(p.input) {
+		c := p.input[p.pos]
+		p.pos++
+
+		switch c {
+		case '}':
+			return toString(p.fields[sb.String()]), nil
+		case '\\':
+			if p.pos < len(p.input) {
+				sb.WriteByte(p.input[p.pos])
+				p.pos++
+			}
+		default:
+			sb.WriteByte(c)
+		}
+	}
+
+	return nil, fmt.Errorf("unterminated field reference")
+}
+
+func (p *formulaParser) call() (any, error) {
+	start := p.pos
+	for p.pos < len(p.input) && (p.input[p.pos] == '_' || unicode.IsLetter(rune(p.input[p.pos]))) {
+		p.pos++
+	}
+	name := p.input[start:p.pos]
+
+	if err := p.expect('('); err != nil {
+		return nil, err
+	}
+
+	var args []any
+	if p.skipSpace(); p.pos < len(p.input) && p.input[p.pos] == ')' {
+		p.pos++
+	} else {
+		for {
+			arg, err := p.expr()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+
+			if p.skipSpace(); p.pos < len(p.input) && p.input[p.pos] == ',' {
+				p.pos++
+				continue
+			}
+
+			if err := p.expect(')'); err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+
+	return callFunction(name, args)
+}
+
+// callFunction applies the airtable functions the vq/formula helpers use.
+func callFunction(name string, args []any) (any, error) {
+	switch name {
+	case "TRUE", "FALSE":
+		if len(args) != 0 {
+			return nil, fmt.Errorf("%s expects no arguments, got %d", name, len(args))
+		}
+		return name == "TRUE", nil
+	case "AND", "OR":
+		// AND is true unless an argument is false, OR is false unless one is true.
+		all := name == "AND"
+		for _, arg := range args {
+			condition, ok := arg.(bool)
+			if !ok {
+				return nil, fmt.Errorf("%s expects conditions, got %T", name, arg)
+			}
+			if condition != all {
+				return condition, nil
+			}
+		}
+		return all, nil
+	case "LOWER", "ARRAYJOIN":
+		// fields are read as text with lists already joined, so ARRAYJOIN passes through.
+		s, err := stringArgs(name, args, 1)
+		if err != nil {
+			return nil, err
+		}
+		if name == "LOWER" {
+			return strings.ToLower(s[0]), nil
+		}
+		return s[0], nil
+	case "SEARCH":
+		s, err := stringArgs(name, args, 2)
+		if err != nil {
+			return nil, err
+		}
+		// airtable returns the position, the filters only use it as a condition.
+		return strings.Contains(s[1], s[0]), nil
+	default:
+		return nil, fmt.Errorf("unsupported function %s", name)
+	}
+}
+
+func stringArgs(name string, args []any, n int) ([]string, error) {
+	if len(args) != n {
+		return nil, fmt.Errorf("%s expects %d arguments, got %d", name, n, len(args))
+	}
+
+	s := make([]string, n)
+	for i, arg := range args {
+		text, ok := arg.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s expects text, got %T", name, arg)
+		}
+		s[i] = text
+	}
+
+	return s, nil
+}
+
+func (p *formulaParser) expect(c byte) error {
+	p.skipSpace()
+	if p.pos >= len(p.input) || p.input[p.pos] != c {
+		return fmt.Errorf("expected %q at %d", c, p.pos)
+	}
+	p.pos++
+	return nil
+}
+
+func (p *formulaParser) skipSpace() {
+	for p.pos < len(p.input) && unicode.IsSpace(rune(p.input[p.pos])) {
+		p.pos++
+	}
+}
+
+// toString reads a field as text, joining linked fields like airtable's ARRAYJOIN.
+func toString(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []any:
+		parts := make([]string, len(v))
+		for i, item := range v {
+			parts[i] = toString(item)
+		}
+		return strings.Join(parts, ",")
+	default:
+		return fmt.Sprint(v)
+	}
+}
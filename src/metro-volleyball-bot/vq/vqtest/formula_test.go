@@ -0,0 +1,68 @@
+package vqtest
+
+import (
+	"testing"
+
+	"github.com/brewinski/home-lab-server/src/metro-volleyball-bot/vq/formula"
+)
+
+func TestEvalFormula(t *testing.T) {
+	fields := map[string]any{
+		"LinkTmA":  "Aces",
+		"LinkTmB":  "APG",
+		"Division": "MD",
+		"Teams":    []any{"Aces", "APG"},
+		"Rank":     float64(2),
+	}
+
+	tests := []struct {
+		name    string
+		formula string
+		want    bool
+		wantErr bool
+	}{
+		{name: "empty formulas match everything", formula: "", want: true},
+		{name: "contains matches case insensitively", formula: formula.Contains("LinkTmA", "ACES").String(), want: true},
+		{name: "contains matches part of the value", formula: formula.Contains("LinkTmA", "ce").String(), want: true},
+		{name: "contains doesn't match other teams", formula: formula.Contains("LinkTmA", "apg").String(), want: false},
+		{name: "contains treats quotes as text", formula: formula.Contains("LinkTmA", `aces") , TRUE(`).String(), want: false},
+		{name: "contains reads linked fields", formula: formula.Contains("Teams", "apg").String(), want: true},
+		{name: "missing fields are empty", formula: formula.Contains("Missing", "aces").String(), want: false},
+		{name: "equals matches the whole value", formula: formula.Equals("Division", "md").String(), want: true},
+		{name: "equals doesn't match part of the value", formula: formula.Equals("LinkTmA", "ace").String(), want: false},
+		{name: "equals reads numbers as text", formula: formula.Equals("Rank", "2").String(), want: true},
+		{
+			name:    "and requires every expression",
+			formula: formula.And(formula.Contains("LinkTmA", "aces"), formula.Contains("LinkTmB", "aces")).String(),
+			want:    false,
+		},
+		{
+			name: "or requires any expression",
+			formula: formula.Or(
+				formula.And(formula.Contains("LinkTmA", "apg"), formula.Contains("LinkTmB", "aces")),
+				formula.And(formula.Contains("LinkTmA", "aces"), formula.Contains("LinkTmB", "apg")),
+			).String(),
+			want: true,
+		},
+		{name: "empty and is true", formula: formula.And().String(), want: true},
+		{name: "empty or is false", formula: formula.Or().String(), want: false},
+		{name: "unsupported functions are an error", formula: formula.Not(formula.Equals("Division", "md")).String(), wantErr: true},
+		{name: "unsupported comparisons are an error", formula: formula.Lt(formula.Field("Rank"), formula.Number(10)).String(), wantErr: true},
+		{name: "text isn't a condition", formula: formula.Lower(formula.Field("Division")).String(), wantErr: true},
+		{name: "and of text is an error", formula: formula.And(formula.Field("Division")).String(), wantErr: true},
+		{name: "unterminated strings are an error", formula: `LOWER("aces)`, wantErr: true},
+		{name: "trailing input is an error", formula: formula.True().String() + " TRUE()", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := evalFormula(tt.formula, fields)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("evalFormula(%s) error = %v, wantErr %v", tt.formula, err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("evalFormula(%s) = %v, want %v", tt.formula, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package vqtest
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/brewinski/home-lab-server/src/metro-volleyball-bot/cfg"
+	"github.com/brewinski/home-lab-server/src/metro-volleyball-bot/vq"
+)
+
+var (
+	record       = flag.Bool("record", false, "record the fixtures in testdata from the live competition")
+	recordConfig = flag.String("record-config", "", "the competitions config to record from, relative to this package. its first competition needs the games ids")
+)
+
+// TestRecordFixtures replaces the fixtures with the games and every division's ladder of a
+// live competition. It only runs with -record as it needs network access.
+func TestRecordFixtures(t *testing.T) {
+	if !*record {
+		t.Skip("run with -record to record the fixtures")
+	}
+
+	config := cfg.Default()
+	if *recordConfig != "" {
+		loaded, err := cfg.Load(*recordConfig)
+		if err != nil {
+			t.Fatalf("cfg.Load() error = %v", err)
+		}
+		config = loaded
+	}
+
+	competition, err := config.Competition("")
+	if err != nil {
+		t.Fatalf("Config.Competition() error = %v", err)
+	}
+
+	if !competition.HasGames() {
+		t.Fatalf("competition %s has no games ids, record with a config that has them", competition.Name)
+	}
+
+	client := vq.NewClient(vq.ClientConfig{
+		ApiUrl:       competition.BaseURL,
+		LadderPath:   competition.Ladder.Path(),
+		LadderPageID: competition.Ladder.PageID,
+		GamesPath:    competition.Games.Path(),
+		GamesPageID:  competition.Games.PageID,
+		Division:     competition.Division,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	games, err := client.AllGames(ctx)
+	if err != nil {
+		t.Fatalf("Client.AllGames() error = %v", err)
+	}
+
+	ladder, err := client.GetDivisionLadder(ctx, "")
+	if err != nil {
+		t.Fatalf("Client.GetDivisionLadder() error = %v", err)
+	}
+
+	// the records are rebuilt from the decoded fields, which drops everything the bot
+	// doesn't read.
+	gameRecords := make([]map[string]any, 0, len(games))
+	for _, game := range games {
+		gameRecords = append(gameRecords, gameRecord(game))
+	}
+
+	ladderRecords := make([]map[string]any, 0, len(ladder.Records))
+	for _, record := range ladder.Records {
+		ladderRecords = append(ladderRecords, toRecord(record.ID, record.Fields))
+	}
+
+	writeFixture(t, "testdata/games.json", gameRecords)
+	writeFixture(t, "testdata/ladder.json", ladderRecords)
+}
+
+func writeFixture(t *testing.T, file string, records []map[string]any) {
+	t.Helper()
+
+	data, err := json.MarshalIndent(map[string]any{"records": records, "offset": ""}, "", "  ")
+	if err != nil {
+		t.Fatalf("vqtest: unable to encode fixture %s: %v", file, err)
+	}
+
+	if err := os.WriteFile(filepath.FromSlash(file), append(data, '\n'), 0o644); err != nil {
+		t.Fatalf("vqtest: unable to write fixture %s: %v", file, err)
+	}
+
+	t.Logf("recorded %d records to %s", len(records), file)
+}
@@ -0,0 +1,378 @@
+// Package vqtest provides a fake softr airtable proxy for testing code that uses the vq
+// client without network access. The server serves the games and ladder fixtures in
+// testdata, honours page_size, offset and the filter formulas the client builds, and can
+// be told to fail.
+//
+// The fixtures are hand-written in the shape of softr's responses until they're recorded
+// from a live season with:
+//
+//	go test ./vq/vqtest -run TestRecordFixtures -record -record-config ../../config.yaml
+//
+// Recording keeps only the fields the bot reads, so nothing else softr returns ends up in
+// the repo. The tests that name fixture teams need updating for the recorded season.
+package vqtest
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/brewinski/home-lab-server/src/metro-volleyball-bot/vq"
+)
+
+//go:embed testdata/*.json
+var fixtures embed.FS
+
+// Endpoint identifies one of the faked softr endpoints.
+type Endpoint string
+
+const (
+	Games  Endpoint = "games"
+	Ladder Endpoint = "ladder"
+)
+
+// Paths and page ids the server expects. ClientConfig sets them on the client.
+const (
+	LadderPath   = "/Ladder/records?block_id=ladder-block"
+	LadderPageID = "ladder-page"
+	GamesPath    = "/Competition%20Manager/records?block_id=games-block"
+	GamesPageID  = "games-page"
+	// Division is the division most of the ladder fixture belongs to.
+	Division = "MD"
+)
+
+// the airtable proxy rejects pages larger than this.
+const maxPageSize = 100
+
+// Failure is a canned error response.
+type Failure struct {
+	Status     int
+	Body       string
+	RetryAfter string
+}
+
+var (
+	TooManyRequests = Failure{Status: http.StatusTooManyRequests, Body: `{"error":"rate limited"}`, RetryAfter: "0"}
+	ServerError     = Failure{Status: http.StatusInternalServerError, Body: `{"error":"internal error"}`}
+	MalformedJSON   = Failure{Status: http.StatusOK, Body: `{"records": [`}
+)
+
+// Request is a request received by the server.
+type Request struct {
+	Endpoint        Endpoint
+	PageID          string
+	PageSize        int
+	Offset          string
+	View            string
+	FilterByFormula string
+}
+
+// Server is a fake softr airtable proxy. It's safe for concurrent use.
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	records  map[Endpoint][]map[string]any
+	failures map[Endpoint][]Failure
+	requests []Request
+}
+
+// NewServer starts a server loaded with the fixtures in testdata. It's closed when the test
+// finishes.
+func NewServer(t testing.TB) *Server {
+	t.Helper()
+
+	s := &Server{
+		records:  map[Endpoint][]map[string]any{},
+		failures: map[Endpoint][]Failure{},
+	}
+
+	for endpoint, file := range map[Endpoint]string{Games: "testdata/games.json", Ladder: "testdata/ladder.json"} {
+		records, err := loadFixture(file)
+		if err != nil {
+			t.Fatalf("vqtest.NewServer() unable to load fixture %s: %v", file, err)
+		}
+		s.records[endpoint] = records
+	}
+
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+// ClientConfig returns a client config pointed at the server. Retries are kept short so
+// tests exercising failures stay fast.
+func (s *Server) ClientConfig() vq.ClientConfig {
+	return vq.ClientConfig{
+		Client:         s.Client(),
+		ApiUrl:         s.URL,
+		LadderPath:     LadderPath,
+		LadderPageID:   LadderPageID,
+		GamesPath:      GamesPath,
+		GamesPageID:    GamesPageID,
+		Division:       Division,
+		RetryBaseDelay: time.Millisecond,
+		RetryMaxDelay:  5 * time.Millisecond,
+	}
+}
+
+// NewClient returns a client for the server.
+func (s *Server) NewClient() *vq.Client {
+	return vq.NewClient(s.ClientConfig())
+}
+
+// SetGames replaces the games served. Each game gets a Display_Identifier naming the
+// teams so team filters work.
+func (s *Server) SetGames(games []vq.GameRecord) {
+	records := make([]map[string]any, 0, len(games))
+	for _, game := range games {
+		records = append(records, gameRecord(game))
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.records[Games] = records
+}
+
+// SetLadder replaces the ladder served. Records are served in the order given.
+func (s *Server) SetLadder(ladder []vq.LadderRecord) {
+	records := make([]map[string]any, 0, len(ladder))
+	for _, record := range ladder {
+		records = append(records, toRecord(record.ID, record.Fields))
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.records[Ladder] = records
+}
+
+// Fail queues failures for the endpoint. Each request uses the next failure until they
+// run out, after which requests succeed again.
+func (s *Server) Fail(endpoint Endpoint, failures ...Failure) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.failures[endpoint] = append(s.failures[endpoint], failures...)
+}
+
+// Requests returns the requests received for the endpoint.
+func (s *Server) Requests(endpoint Endpoint) []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var requests []Request
+	for _, request := range s.requests {
+		if request.Endpoint == endpoint {
+			requests = append(requests, request)
+		}
+	}
+
+	return requests
+}
+
+type requestBody struct {
+	PageSize        int    `json:"page_size"`
+	View            string `json:"view"`
+	FilterByFormula string `json:"filter_by_formula"`
+	Offset          string `json:"offset"`
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	endpoint, pageID, ok := route(r)
+	if !ok {
+		writeError(w, http.StatusNotFound, "NOT_FOUND", "unknown endpoint "+r.URL.String())
+		return
+	}
+
+	if got := r.Header.Get("softr-page-id"); got != pageID {
+		writeError(w, http.StatusBadRequest, "INVALID_PAGE", fmt.Sprintf("softr-page-id %q, want %q", got, pageID))
+		return
+	}
+
+	var body requestBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST_BODY", err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	s.requests = append(s.requests, Request{
+		Endpoint:        endpoint,
+		PageID:          r.Header.Get("softr-page-id"),
+		PageSize:        body.PageSize,
+		Offset:          body.Offset,
+		View:            body.View,
+		FilterByFormula: body.FilterByFormula,
+	})
+
+	var failure *Failure
+	if queued := s.failures[endpoint]; len(queued) > 0 {
+		failure = &queued[0]
+		s.failures[endpoint] = queued[1:]
+	}
+
+	records := s.records[endpoint]
+	s.mu.Unlock()
+
+	if failure != nil {
+		if failure.RetryAfter != "" {
+			w.Header().Set("Retry-After", failure.RetryAfter)
+		}
+		w.WriteHeader(failure.Status)
+		w.Write([]byte(failure.Body))
+		return
+	}
+
+	if body.PageSize < 0 || body.PageSize > maxPageSize {
+		writeError(w, http.StatusUnprocessableEntity, "INVALID_PAGE_SIZE", fmt.Sprintf("page_size must be between 1 and %d", maxPageSize))
+		return
+	}
+
+	pageSize := body.PageSize
+	if pageSize == 0 {
+		pageSize = maxPageSize
+	}
+
+	var matched []map[string]any
+	for _, record := range records {
+		ok, err := evalFormula(body.FilterByFormula, record["fields"].(map[string]any))
+		if err != nil {
+			writeError(w, http.StatusUnprocessableEntity, "INVALID_FILTER_BY_FORMULA", err.Error())
+			return
+		}
+		if ok {
+			matched = append(matched, record)
+		}
+	}
+
+	start := 0
+	if body.Offset != "" {
+		start, ok = parseOffset(body.Offset)
+		if !ok || start > len(matched) {
+			writeError(w, http.StatusUnprocessableEntity, "INVALID_OFFSET_VALUE", "invalid offset "+body.Offset)
+			return
+		}
+	}
+
+	end := min(start+pageSize, len(matched))
+	response := map[string]any{"records": matched[start:end]}
+	if end < len(matched) {
+		response["offset"] = formatOffset(end)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// route matches the request to an endpoint by its path and block id.
+func route(r *http.Request) (Endpoint, string, bool) {
+	for endpoint, path := range map[Endpoint]string{Games: GamesPath, Ladder: LadderPath} {
+		want, _ := http.NewRequest(http.MethodPost, path, nil)
+		if r.URL.EscapedPath() == want.URL.EscapedPath() && r.URL.Query().Get("block_id") == want.URL.Query().Get("block_id") {
+			if endpoint == Games {
+				return endpoint, GamesPageID, true
+			}
+			return endpoint, LadderPageID, true
+		}
+	}
+
+	return "", "", false
+}
+
+func writeError(w http.ResponseWriter, status int, errorType string, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]any{
+		"error": map[string]string{"type": errorType, "message": message},
+	})
+}
+
+// offsets look like airtable's opaque cursors but encode the index of the next record.
+func formatOffset(index int) string {
+	return "itr" + strconv.Itoa(index) + "/rec"
+}
+
+func parseOffset(offset string) (int, bool) {
+	trimmed := strings.TrimSuffix(strings.TrimPrefix(offset, "itr"), "/rec")
+	index, err := strconv.Atoi(trimmed)
+	return index, err == nil && index >= 0
+}
+
+func loadFixture(file string) ([]map[string]any, error) {
+	data, err := fixtures.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var body struct {
+		Records []map[string]any `json:"records"`
+	}
+	if err := json.Unmarshal(data, &body); err != nil {
+		return nil, err
+	}
+
+	return body.Records, nil
+}
+
+// toRecord converts typed fields into the loosely typed record the server filters on.
+func toRecord(id string, fields any) map[string]any {
+	data, _ := json.Marshal(fields)
+
+	var fieldMap map[string]any
+	json.Unmarshal(data, &fieldMap)
+
+	return map[string]any{"id": id, "fields": fieldMap}
+}
+
+// gameRecord is the game as the server serves it, with the Display_Identifier naming the
+// teams that the team filters search.
+func gameRecord(game vq.GameRecord) map[string]any {
+	record := toRecord(game.ID, game.Fields)
+	record["fields"].(map[string]any)["Display_Identifier"] = fmt.Sprintf("R%s %s v %s", game.Fields.Round, game.Fields.TeamA, game.Fields.TeamB)
+
+	return record
+}
+
+// GamesFixture returns the fixture games served by a new server.
+func GamesFixture(t testing.TB) []vq.GameRecord {
+	t.Helper()
+
+	var body vq.GetGameResponseBody
+	readFixture(t, "testdata/games.json", &body)
+
+	return body.Records
+}
+
+// LadderFixture returns the fixture ladder served by a new server, every division
+// included.
+func LadderFixture(t testing.TB) []vq.LadderRecord {
+	t.Helper()
+
+	var body vq.GetLadderResponseBody
+	readFixture(t, "testdata/ladder.json", &body)
+
+	return body.Records
+}
+
+func readFixture(t testing.TB, file string, v any) {
+	t.Helper()
+
+	data, err := fixtures.ReadFile(file)
+	if err != nil {
+		t.Fatalf("vqtest: unable to read fixture %s: %v", file, err)
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		t.Fatalf("vqtest: unable to decode fixture %s: %v", file, err)
+	}
+}
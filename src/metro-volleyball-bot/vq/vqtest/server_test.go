@@ -0,0 +1,197 @@
+package vqtest_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/brewinski/home-lab-server/src/metro-volleyball-bot/vq"
+	"github.com/brewinski/home-lab-server/src/metro-volleyball-bot/vq/vqtest"
+)
+
+func TestServer_Ladder(t *testing.T) {
+	s := vqtest.NewServer(t)
+	c := s.NewClient()
+
+	ladder, err := c.GetLadder(context.Background())
+	if err != nil {
+		t.Fatalf("Client.GetLadder() error = %v", err)
+	}
+
+	var division int
+	for _, record := range vqtest.LadderFixture(t) {
+		if record.Fields.Division == vqtest.Division {
+			division++
+		}
+	}
+
+	if len(ladder.Records) != division {
+		t.Errorf("Client.GetLadder() records = %d, want the %d teams in the division", len(ladder.Records), division)
+	}
+
+	for _, record := range ladder.Records {
+		if record.Fields.Division != vqtest.Division {
+			t.Errorf("Client.GetLadder() returned team %s from division %s", record.Fields.TeamName, record.Fields.Division)
+		}
+	}
+}
+
+func TestServer_Pagination(t *testing.T) {
+	s := vqtest.NewServer(t)
+	c := s.NewClient()
+
+	page, err := c.GetGames(context.Background(), 4, "")
+	if err != nil {
+		t.Fatalf("Client.GetGames() error = %v", err)
+	}
+
+	if len(page.Records) != 4 || page.Offset == "" {
+		t.Errorf("Client.GetGames() = %d records offset %q, want 4 records and an offset", len(page.Records), page.Offset)
+	}
+
+	games, err := c.AllGames(context.Background())
+	if err != nil {
+		t.Fatalf("Client.AllGames() error = %v", err)
+	}
+
+	if want := len(vqtest.GamesFixture(t)); len(games) != want {
+		t.Errorf("Client.AllGames() = %d records, want %d", len(games), want)
+	}
+
+	if _, err := c.GetGames(context.Background(), 101, ""); err == nil {
+		t.Errorf("Client.GetGames() expected an error for a page size over 100")
+	}
+}
+
+func TestServer_Filters(t *testing.T) {
+	s := vqtest.NewServer(t)
+	c := s.NewClient()
+
+	tests := []struct {
+		name  string
+		team  string
+		games func(team string) ([]vq.GameRecord, error)
+		match func(game vq.GameRecord) bool
+	}{
+		{
+			name: "games by team only return the team's games",
+			team: "ACES",
+			games: func(team string) ([]vq.GameRecord, error) {
+				return c.AllGamesByTeam(context.Background(), team)
+			},
+			match: func(game vq.GameRecord) bool {
+				return game.Fields.TeamA == "Aces" || game.Fields.TeamB == "Aces"
+			},
+		},
+		{
+			name: "games by duty team only return the team's duties",
+			team: "aces",
+			games: func(team string) ([]vq.GameRecord, error) {
+				return c.AllGamesByTeamAndDuty(context.Background(), team)
+			},
+			match: func(game vq.GameRecord) bool {
+				return game.Fields.DutyTeam == "Aces"
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			games, err := tt.games(tt.team)
+			if err != nil {
+				t.Fatalf("error = %v", err)
+			}
+
+			want := 0
+			for _, game := range vqtest.GamesFixture(t) {
+				if tt.match(game) {
+					want++
+				}
+			}
+
+			if len(games) != want || want == 0 {
+				t.Errorf("games = %d, want %d", len(games), want)
+			}
+
+			for _, game := range games {
+				if !tt.match(game) {
+					t.Errorf("unexpected game %+v", game.Fields)
+				}
+			}
+		})
+	}
+
+	t.Run("quotes in team names don't break the filter", func(t *testing.T) {
+		games, err := c.AllGamesByTeam(context.Background(), `aces") , TRUE(`)
+		if err != nil {
+			t.Fatalf("error = %v", err)
+		}
+
+		if len(games) != 0 {
+			t.Errorf("games = %d, want none", len(games))
+		}
+	})
+}
+
+func TestServer_Failures(t *testing.T) {
+	tests := []struct {
+		name     string
+		failures []vqtest.Failure
+		config   func(config *vq.ClientConfig)
+		wantErr  bool
+	}{
+		{
+			name:     "retryable failures are retried",
+			failures: []vqtest.Failure{vqtest.TooManyRequests, vqtest.ServerError},
+		},
+		{
+			name:     "failures are returned once retries run out",
+			failures: []vqtest.Failure{vqtest.ServerError, vqtest.ServerError},
+			config: func(config *vq.ClientConfig) {
+				config.MaxRetries = 1
+			},
+			wantErr: true,
+		},
+		{
+			name:     "malformed json is an error",
+			failures: []vqtest.Failure{vqtest.MalformedJSON},
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := vqtest.NewServer(t)
+			s.Fail(vqtest.Ladder, tt.failures...)
+
+			config := s.ClientConfig()
+			if tt.config != nil {
+				tt.config(&config)
+			}
+
+			_, err := vq.NewClient(config).GetLadder(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Client.GetLadder() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestServer_SetData(t *testing.T) {
+	s := vqtest.NewServer(t)
+	s.SetLadder([]vq.LadderRecord{{ID: "1", Fields: vq.LadderFields{Rank: "1", TeamName: "Aces", Division: vqtest.Division}}})
+	s.SetGames([]vq.GameRecord{{ID: "1", Fields: vq.GameFields{TeamA: "Aces", TeamB: "APG", DutyTeam: "Blockers"}}})
+
+	c := s.NewClient()
+
+	ladder, err := c.GetLadder(context.Background())
+	if err != nil || len(ladder.Records) != 1 {
+		t.Errorf("Client.GetLadder() = %v, %v, want the replaced ladder", ladder, err)
+	}
+
+	games, err := c.AllGamesByTeam(context.Background(), "apg")
+	if err != nil || len(games) != 1 {
+		t.Errorf("Client.AllGamesByTeam() = %v, %v, want the replaced games", games, err)
+	}
+
+	if requests := s.Requests(vqtest.Games); len(requests) != 1 || requests[0].PageID != vqtest.GamesPageID {
+		t.Errorf("Server.Requests() = %+v, want a single games request", requests)
+	}
+}